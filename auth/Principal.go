@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"BlogApplication/model"
+	"context"
+)
+
+// Roles a principal can carry in its token's "roles" claim.
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	// RoleInternal marks other services of the platform, which may act on
+	// behalf of the user named in the request payload.
+	RoleInternal = "internal"
+)
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	UserId int64
+	Roles  []string
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+type trustedCallerKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal stored in ctx, if any.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// WithTrustedCaller marks ctx as coming from a trusted internal caller that
+// didn't present a token. It's only set when trusted callers are enabled.
+func WithTrustedCaller(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedCallerKey{}, true)
+}
+
+// ActorId returns the id of the user an RPC acts as. Callers act as
+// themselves; internal services and trusted callers without a token act as
+// the user named in the payload. Anyone else gets an Unauthenticated error.
+func ActorId(ctx context.Context, payloadUserId int64) (int64, error) {
+	if principal, ok := PrincipalFrom(ctx); ok {
+		if principal.HasRole(RoleInternal) && payloadUserId != 0 {
+			return payloadUserId, nil
+		}
+		return principal.UserId, nil
+	}
+	if IsTrustedCaller(ctx) {
+		return payloadUserId, nil
+	}
+	return 0, model.Unauthenticated("missing caller identity")
+}
+
+// IsTrustedCaller reports whether ctx was marked by WithTrustedCaller.
+func IsTrustedCaller(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedCallerKey{}).(bool)
+	return trusted
+}
+
+// SystemContext returns ctx acting as the service itself, for work that isn't
+// triggered by a user, such as handling events.
+func SystemContext(ctx context.Context) context.Context {
+	return WithPrincipal(ctx, &Principal{Roles: []string{RoleInternal}})
+}
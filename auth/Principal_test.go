@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"BlogApplication/model"
+	"context"
+	"testing"
+)
+
+func TestActorId(t *testing.T) {
+	user := WithPrincipal(context.Background(), &Principal{UserId: 7})
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		payload int64
+		want    int64
+	}{
+		{"users act as themselves", user, 9, 7},
+		{"internal services act for the payload's user", SystemContext(context.Background()), 9, 9},
+		{"internal services without a payload user", WithPrincipal(context.Background(), &Principal{UserId: 3, Roles: []string{RoleInternal}}), 0, 3},
+		{"trusted callers act for the payload's user", WithTrustedCaller(context.Background()), 9, 9},
+	}
+	for _, test := range tests {
+		if got, err := ActorId(test.ctx, test.payload); err != nil || got != test.want {
+			t.Errorf("%s: ActorId = %d, %v; want %d", test.name, got, err, test.want)
+		}
+	}
+
+	if _, err := ActorId(context.Background(), 9); model.KindOf(err) != model.KindUnauthenticated {
+		t.Errorf("anonymous ActorId = %v, want unauthenticated", err)
+	}
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"BlogApplication/model"
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Verifier checks the signature and claims of bearer JWTs. HS* tokens are
+// checked against HMACSecret, RS* tokens against RSAKeys by key id. A token
+// whose algorithm has no key configured is rejected.
+type Verifier struct {
+	HMACSecret []byte
+	RSAKeys    map[string]*rsa.PublicKey
+	// Issuer and Audience are checked when set.
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking exp and nbf.
+	Leeway time.Duration
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type claims struct {
+	Subject   json.Number     `json:"sub"`
+	Roles     []string        `json:"roles"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+var hashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+// Verify parses token and returns the principal it identifies.
+func (verifier *Verifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, model.Unauthenticated("malformed token")
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, model.Unauthenticated("malformed token header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, model.Unauthenticated("malformed token signature")
+	}
+	if err := verifier.verifySignature(h, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, model.Unauthenticated("malformed token claims")
+	}
+	if err := verifier.checkClaims(&c, time.Now()); err != nil {
+		return nil, err
+	}
+
+	userId, err := strconv.ParseInt(c.Subject.String(), 10, 64)
+	if err != nil {
+		return nil, model.Unauthenticated("token subject %q isn't a user id", c.Subject)
+	}
+	return &Principal{UserId: userId, Roles: c.Roles}, nil
+}
+
+func (verifier *Verifier) verifySignature(h header, signingInput string, signature []byte) error {
+	if len(h.Alg) != 5 {
+		return model.Unauthenticated("unsupported token algorithm %q", h.Alg)
+	}
+	hash, ok := hashes[h.Alg[2:]]
+	if !ok {
+		return model.Unauthenticated("unsupported token algorithm %q", h.Alg)
+	}
+
+	switch h.Alg[:2] {
+	case "HS":
+		if len(verifier.HMACSecret) == 0 {
+			return model.Unauthenticated("HMAC tokens aren't accepted")
+		}
+		mac := hmac.New(hash.New, verifier.HMACSecret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return model.Unauthenticated("invalid token signature")
+		}
+		return nil
+	case "RS":
+		key, ok := verifier.RSAKeys[h.Kid]
+		if !ok {
+			return model.Unauthenticated("unknown token key %q", h.Kid)
+		}
+		digest := hash.New()
+		digest.Write([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, hash, digest.Sum(nil), signature); err != nil {
+			return model.Unauthenticated("invalid token signature")
+		}
+		return nil
+	default:
+		return model.Unauthenticated("unsupported token algorithm %q", h.Alg)
+	}
+}
+
+func (verifier *Verifier) checkClaims(c *claims, now time.Time) error {
+	if c.ExpiresAt == nil {
+		return model.Unauthenticated("token has no expiry")
+	}
+	if now.After(time.Unix(*c.ExpiresAt, 0).Add(verifier.Leeway)) {
+		return model.Unauthenticated("token expired")
+	}
+	if c.NotBefore != nil && now.Add(verifier.Leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return model.Unauthenticated("token not valid yet")
+	}
+	if verifier.Issuer != "" && c.Issuer != verifier.Issuer {
+		return model.Unauthenticated("unexpected token issuer %q", c.Issuer)
+	}
+	if verifier.Audience != "" && !hasAudience(c.Audience, verifier.Audience) {
+		return model.Unauthenticated("token isn't meant for %q", verifier.Audience)
+	}
+	return nil
+}
+
+// hasAudience reports whether the aud claim, a string or a list of strings,
+// contains audience.
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		for _, a := range list {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
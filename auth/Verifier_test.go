@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"BlogApplication/model"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-secret")
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func hs256Token(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	signingInput := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func rs256Token(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signingInput := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "7",
+		"roles": []string{RoleModerator},
+		"iss":   "https://auth.example.com",
+		"aud":   []string{"other", "blog-service"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyAcceptsValidTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &Verifier{
+		HMACSecret: testSecret,
+		RSAKeys:    map[string]*rsa.PublicKey{"key-1": &key.PublicKey},
+		Issuer:     "https://auth.example.com",
+		Audience:   "blog-service",
+	}
+
+	for name, token := range map[string]string{
+		"HS256": hs256Token(t, validClaims()),
+		"RS256": rs256Token(t, key, "key-1", validClaims()),
+	} {
+		principal, err := verifier.Verify(token)
+		if err != nil {
+			t.Errorf("%s: Verify: %v", name, err)
+			continue
+		}
+		if principal.UserId != 7 || !principal.HasRole(RoleModerator) || principal.HasRole(RoleAdmin) {
+			t.Errorf("%s: principal = %+v", name, principal)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &Verifier{
+		HMACSecret: testSecret,
+		RSAKeys:    map[string]*rsa.PublicKey{"key-1": &key.PublicKey},
+		Issuer:     "https://auth.example.com",
+		Audience:   "blog-service",
+		Leeway:     time.Minute,
+	}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	tampered := hs256Token(t, validClaims())
+	tampered = tampered[:len(tampered)-2] + "AA"
+
+	tests := map[string]string{
+		"malformed":           "not-a-token",
+		"tampered signature":  tampered,
+		"unknown key":         rs256Token(t, key, "key-2", validClaims()),
+		"unsupported alg":     encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + ".",
+		"no expiry":           hs256Token(t, with("exp", nil)),
+		"expired":             hs256Token(t, with("exp", time.Now().Add(-2*time.Minute).Unix())),
+		"not valid yet":       hs256Token(t, with("nbf", time.Now().Add(2*time.Minute).Unix())),
+		"other issuer":        hs256Token(t, with("iss", "https://evil.example.com")),
+		"other audience":      hs256Token(t, with("aud", "other")),
+		"subject isn't an id": hs256Token(t, with("sub", "alice")),
+	}
+	for name, token := range tests {
+		if _, err := verifier.Verify(token); model.KindOf(err) != model.KindUnauthenticated {
+			t.Errorf("%s: Verify = %v, want unauthenticated", name, err)
+		}
+	}
+
+	// Within the leeway a token still passes.
+	if _, err := verifier.Verify(hs256Token(t, with("exp", time.Now().Add(-30*time.Second).Unix()))); err != nil {
+		t.Errorf("token expired within the leeway: %v", err)
+	}
+	// HMAC tokens aren't accepted when no secret is configured.
+	rsaOnly := &Verifier{RSAKeys: verifier.RSAKeys}
+	if _, err := rsaOnly.Verify(hs256Token(t, validClaims())); model.KindOf(err) != model.KindUnauthenticated {
+		t.Errorf("HS256 token without a secret: %v", err)
+	}
+}
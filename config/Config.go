@@ -0,0 +1,309 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable holding the path of the optional
+// config file.
+const FileEnv = "BLOG_CONFIG_FILE"
+
+// Config holds every setting the service reads at startup. Values come from
+// the defaults, then the config file if there is one, then the environment.
+type Config struct {
+	Mongo     MongoConfig     `yaml:"mongo" toml:"mongo"`
+	Nats      NatsConfig      `yaml:"nats" toml:"nats"`
+	Telemetry TelemetryConfig `yaml:"telemetry" toml:"telemetry"`
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	Features  FeatureConfig   `yaml:"features" toml:"features"`
+	Lifecycle LifecycleConfig `yaml:"lifecycle" toml:"lifecycle"`
+	Health    HealthConfig    `yaml:"health" toml:"health"`
+	Logging   LoggingConfig   `yaml:"logging" toml:"logging"`
+}
+
+type MongoConfig struct {
+	URI              string        `yaml:"uri" toml:"uri" env:"MONGO_URI"`
+	Database         string        `yaml:"database" toml:"database" env:"MONGO_DATABASE"`
+	ConnectTimeout   time.Duration `yaml:"connect_timeout" toml:"connect_timeout" env:"MONGO_CONNECT_TIMEOUT"`
+	OperationTimeout time.Duration `yaml:"operation_timeout" toml:"operation_timeout" env:"MONGO_OPERATION_TIMEOUT"`
+	// AllowStandalone lets the service run against a standalone server, which
+	// can't run transactions, so writes and their events aren't atomic. Only
+	// meant for local development.
+	AllowStandalone bool `yaml:"allow_standalone" toml:"allow_standalone" env:"MONGO_ALLOW_STANDALONE"`
+}
+
+type NatsConfig struct {
+	URL string `yaml:"url" toml:"url" env:"NATS_URL"`
+	// CredentialsFile is a .creds file with the user JWT and nkey seed.
+	CredentialsFile string `yaml:"credentials_file" toml:"credentials_file" env:"NATS_CREDENTIALS_FILE"`
+	User            string `yaml:"user" toml:"user" env:"NATS_USER"`
+	Password        string `yaml:"password" toml:"password" env:"NATS_PASSWORD"`
+	Token           string `yaml:"token" toml:"token" env:"NATS_TOKEN"`
+	// CAFile verifies the server certificate when the URL uses tls://.
+	CAFile string `yaml:"ca_file" toml:"ca_file" env:"NATS_CA_FILE"`
+}
+
+type TelemetryConfig struct {
+	ServiceName  string `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME"`
+	OTLPEndpoint string `yaml:"otlp_endpoint" toml:"otlp_endpoint" env:"OTLP_ENDPOINT"`
+	OTLPInsecure bool   `yaml:"otlp_insecure" toml:"otlp_insecure" env:"OTLP_INSECURE"`
+	// SampleRatio is the fraction of new traces that are recorded. Traces
+	// started upstream keep the caller's sampling decision.
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACE_SAMPLE_RATIO"`
+	// MaxTextLength is how many characters of titles, comments and other
+	// free text go on spans. Zero redacts them.
+	MaxTextLength int `yaml:"max_text_length" toml:"max_text_length" env:"TRACE_MAX_TEXT_LENGTH"`
+	// DeniedAttributes are span attribute keys that are never exported,
+	// whichever library sets them. The env variable is comma separated.
+	DeniedAttributes []string `yaml:"denied_attributes" toml:"denied_attributes" env:"TRACE_DENIED_ATTRIBUTES"`
+	// MetricsAddress serves Prometheus metrics on /metrics. Empty disables it.
+	MetricsAddress string `yaml:"metrics_address" toml:"metrics_address" env:"METRICS_HTTP_ADDRESS"`
+	// OTLPMetrics also pushes metrics to OTLPEndpoint every MetricInterval.
+	// Leave it off when the endpoint is Jaeger, which only accepts traces.
+	OTLPMetrics    bool          `yaml:"otlp_metrics" toml:"otlp_metrics" env:"OTLP_METRICS"`
+	MetricInterval time.Duration `yaml:"metric_interval" toml:"metric_interval" env:"OTLP_METRIC_INTERVAL"`
+}
+
+type ServerConfig struct {
+	ListenAddress string    `yaml:"listen_address" toml:"listen_address" env:"GRPC_LISTEN_ADDRESS"`
+	TLS           TLSConfig `yaml:"tls" toml:"tls"`
+}
+
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled" toml:"enabled" env:"GRPC_TLS_ENABLED"`
+	CertFile string `yaml:"cert_file" toml:"cert_file" env:"GRPC_TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" toml:"key_file" env:"GRPC_TLS_KEY_FILE"`
+	// ClientCAFile turns on mutual TLS: clients must present a certificate
+	// signed by one of these CAs.
+	ClientCAFile string `yaml:"client_ca_file" toml:"client_ca_file" env:"GRPC_TLS_CLIENT_CA_FILE"`
+}
+
+type AuthConfig struct {
+	HMACSecret string        `yaml:"hmac_secret" toml:"hmac_secret" env:"JWT_HMAC_SECRET"`
+	JWKSFile   string        `yaml:"jwks_file" toml:"jwks_file" env:"JWT_JWKS_FILE"`
+	Issuer     string        `yaml:"issuer" toml:"issuer" env:"JWT_ISSUER"`
+	Audience   string        `yaml:"audience" toml:"audience" env:"JWT_AUDIENCE"`
+	Leeway     time.Duration `yaml:"leeway" toml:"leeway" env:"JWT_LEEWAY"`
+	// TrustInternalCallers lets callers without a token act on behalf of the
+	// user named in the request, as long as they present a client certificate
+	// (server.tls.client_ca_file) or connect from one of TrustedNetworks.
+	TrustInternalCallers bool `yaml:"trust_internal_callers" toml:"trust_internal_callers" env:"AUTH_TRUST_INTERNAL_CALLERS"`
+	// TrustedNetworks are CIDR ranges, such as 10.0.0.0/8, that internal
+	// callers connect from.
+	TrustedNetworks []string `yaml:"trusted_networks" toml:"trusted_networks" env:"AUTH_TRUSTED_NETWORKS"`
+}
+
+type FeatureConfig struct {
+	Reflection       bool          `yaml:"reflection" toml:"reflection" env:"FEATURE_REFLECTION"`
+	PublishScheduler bool          `yaml:"publish_scheduler" toml:"publish_scheduler" env:"FEATURE_PUBLISH_SCHEDULER"`
+	PublishInterval  time.Duration `yaml:"publish_interval" toml:"publish_interval" env:"PUBLISH_SCHEDULER_INTERVAL"`
+	CommentSaga      bool          `yaml:"comment_saga" toml:"comment_saga" env:"FEATURE_COMMENT_SAGA"`
+	OutboxInterval   time.Duration `yaml:"outbox_interval" toml:"outbox_interval" env:"OUTBOX_RELAY_INTERVAL"`
+	OutboxBatchSize  int64         `yaml:"outbox_batch_size" toml:"outbox_batch_size" env:"OUTBOX_RELAY_BATCH_SIZE"`
+	MaxCommentDepth  int           `yaml:"max_comment_depth" toml:"max_comment_depth" env:"MAX_COMMENT_DEPTH"`
+}
+
+type LifecycleConfig struct {
+	// StartupTimeout is how long startup keeps retrying a dependency that
+	// isn't reachable yet before giving up.
+	StartupTimeout      time.Duration `yaml:"startup_timeout" toml:"startup_timeout" env:"STARTUP_TIMEOUT"`
+	RetryInitialBackoff time.Duration `yaml:"retry_initial_backoff" toml:"retry_initial_backoff" env:"STARTUP_RETRY_INITIAL_BACKOFF"`
+	RetryMaxBackoff     time.Duration `yaml:"retry_max_backoff" toml:"retry_max_backoff" env:"STARTUP_RETRY_MAX_BACKOFF"`
+	// ShutdownTimeout bounds each shutdown step, including waiting for
+	// in-flight RPCs to finish.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type HealthConfig struct {
+	// HTTPAddress serves /healthz and /readyz for orchestrators that can't
+	// use gRPC health checks. Leave it empty to turn the endpoint off.
+	HTTPAddress   string        `yaml:"http_address" toml:"http_address" env:"HEALTH_HTTP_ADDRESS"`
+	CheckInterval time.Duration `yaml:"check_interval" toml:"check_interval" env:"HEALTH_CHECK_INTERVAL"`
+	CheckTimeout  time.Duration `yaml:"check_timeout" toml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+type LoggingConfig struct {
+	// Level is the initial level, one of debug, info, warn or error. It can
+	// be changed at runtime with the SetLogLevel RPC.
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	// Format is json for production or text for reading in a terminal.
+	Format    string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+	AddSource bool   `yaml:"add_source" toml:"add_source" env:"LOG_ADD_SOURCE"`
+}
+
+// Default returns the settings used for anything the file and environment
+// leave out. They match the docker-compose setup.
+func Default() Config {
+	return Config{
+		Mongo: MongoConfig{
+			URI:              "mongodb://blog-database:27017",
+			Database:         "soa",
+			ConnectTimeout:   10 * time.Second,
+			OperationTimeout: 5 * time.Second,
+		},
+		Nats: NatsConfig{
+			URL: "nats://nats:4222",
+		},
+		Telemetry: TelemetryConfig{
+			ServiceName:   "blog-service",
+			OTLPEndpoint:  "jaeger:4318",
+			OTLPInsecure:  true,
+			SampleRatio:   1,
+			MaxTextLength: 32,
+			DeniedAttributes: []string{
+				"request.data",
+				"db.statement",
+				"rpc.request.metadata.authorization",
+			},
+			MetricsAddress: ":9464",
+			MetricInterval: 30 * time.Second,
+		},
+		Server: ServerConfig{
+			ListenAddress: ":8088",
+		},
+		Auth: AuthConfig{
+			Leeway: 30 * time.Second,
+		},
+		Features: FeatureConfig{
+			Reflection:       true,
+			PublishScheduler: true,
+			PublishInterval:  30 * time.Second,
+			CommentSaga:      true,
+			OutboxInterval:   time.Second,
+			OutboxBatchSize:  100,
+			MaxCommentDepth:  5,
+		},
+		Lifecycle: LifecycleConfig{
+			StartupTimeout:      2 * time.Minute,
+			RetryInitialBackoff: time.Second,
+			RetryMaxBackoff:     30 * time.Second,
+			ShutdownTimeout:     15 * time.Second,
+		},
+		Health: HealthConfig{
+			HTTPAddress:   ":8089",
+			CheckInterval: 5 * time.Second,
+			CheckTimeout:  2 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+}
+
+// Load builds the config from the defaults, the file at path (or at
+// $BLOG_CONFIG_FILE when path is empty) and the environment, then validates
+// it. The file format is picked from its extension: .yaml, .yml or .toml.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv(FileEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(&cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every setting that's missing or out of range at once.
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cfg.Mongo.URI != "", "mongo.uri is required")
+	check(cfg.Mongo.Database != "", "mongo.database is required")
+	check(cfg.Mongo.ConnectTimeout > 0, "mongo.connect_timeout must be positive")
+	check(cfg.Mongo.OperationTimeout > 0, "mongo.operation_timeout must be positive")
+
+	check(cfg.Nats.URL != "", "nats.url is required")
+	check(cfg.Nats.Token == "" || cfg.Nats.User == "", "nats.token and nats.user are mutually exclusive")
+	check(cfg.Nats.User == "" || cfg.Nats.Password != "", "nats.password is required with nats.user")
+
+	check(cfg.Telemetry.ServiceName != "", "telemetry.service_name is required")
+	check(cfg.Telemetry.SampleRatio >= 0 && cfg.Telemetry.SampleRatio <= 1, "telemetry.sample_ratio must be between 0 and 1, got %v", cfg.Telemetry.SampleRatio)
+	check(!cfg.Telemetry.OTLPMetrics || cfg.Telemetry.MetricInterval > 0, "telemetry.metric_interval must be positive when telemetry.otlp_metrics is set")
+	check(cfg.Telemetry.MaxTextLength >= 0, "telemetry.max_text_length must not be negative, got %d", cfg.Telemetry.MaxTextLength)
+
+	check(cfg.Server.ListenAddress != "", "server.listen_address is required")
+	if cfg.Server.TLS.Enabled {
+		check(cfg.Server.TLS.CertFile != "", "server.tls.cert_file is required when TLS is enabled")
+		check(cfg.Server.TLS.KeyFile != "", "server.tls.key_file is required when TLS is enabled")
+	} else {
+		check(cfg.Server.TLS.ClientCAFile == "", "server.tls.client_ca_file needs TLS to be enabled")
+	}
+
+	check(cfg.Auth.HMACSecret != "" || cfg.Auth.JWKSFile != "" || cfg.Auth.TrustInternalCallers,
+		"auth.hmac_secret or auth.jwks_file is required unless auth.trust_internal_callers is set")
+	check(cfg.Auth.Leeway >= 0, "auth.leeway can't be negative")
+	check(!cfg.Auth.TrustInternalCallers || cfg.Server.TLS.ClientCAFile != "" || len(cfg.Auth.TrustedNetworks) > 0,
+		"auth.trust_internal_callers needs server.tls.client_ca_file or auth.trusted_networks")
+	for _, network := range cfg.Auth.TrustedNetworks {
+		_, err := netip.ParsePrefix(network)
+		check(err == nil, "auth.trusted_networks: %q isn't a CIDR range", network)
+	}
+
+	check(!cfg.Features.PublishScheduler || cfg.Features.PublishInterval > 0, "features.publish_interval must be positive")
+	check(cfg.Features.OutboxInterval > 0, "features.outbox_interval must be positive")
+	check(cfg.Features.OutboxBatchSize > 0, "features.outbox_batch_size must be positive")
+	check(cfg.Features.MaxCommentDepth > 0, "features.max_comment_depth must be positive")
+
+	check(cfg.Lifecycle.StartupTimeout > 0, "lifecycle.startup_timeout must be positive")
+	check(cfg.Lifecycle.RetryInitialBackoff > 0, "lifecycle.retry_initial_backoff must be positive")
+	check(cfg.Lifecycle.RetryMaxBackoff >= cfg.Lifecycle.RetryInitialBackoff, "lifecycle.retry_max_backoff can't be less than lifecycle.retry_initial_backoff")
+	check(cfg.Lifecycle.ShutdownTimeout > 0, "lifecycle.shutdown_timeout must be positive")
+
+	check(cfg.Health.CheckInterval > 0, "health.check_interval must be positive")
+	check(cfg.Health.CheckTimeout > 0, "health.check_timeout must be positive")
+	var level slog.Level
+	check(level.UnmarshalText([]byte(cfg.Logging.Level)) == nil, "logging.level must be debug, info, warn or error, got %q", cfg.Logging.Level)
+	check(cfg.Logging.Format == "json" || cfg.Logging.Format == "text", "logging.format must be json or text, got %q", cfg.Logging.Format)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
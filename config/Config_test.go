@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	cfg := Default()
+	cfg.Auth.HMACSecret = "secret"
+	return cfg
+}
+
+func TestDefaultIsValidWithASecret(t *testing.T) {
+	cfg := validConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		want   string
+	}{
+		{"no mongo uri", func(cfg *Config) { cfg.Mongo.URI = "" }, "mongo.uri is required"},
+		{"sample ratio above one", func(cfg *Config) { cfg.Telemetry.SampleRatio = 1.5 }, "telemetry.sample_ratio"},
+		{"nats token and user", func(cfg *Config) { cfg.Nats.Token, cfg.Nats.User, cfg.Nats.Password = "t", "u", "p" }, "mutually exclusive"},
+		{"tls without a key", func(cfg *Config) { cfg.Server.TLS.Enabled, cfg.Server.TLS.CertFile = true, "cert.pem" }, "server.tls.key_file"},
+		{"client ca without tls", func(cfg *Config) { cfg.Server.TLS.ClientCAFile = "ca.pem" }, "needs TLS"},
+		{"no way to authenticate", func(cfg *Config) { cfg.Auth.HMACSecret = "" }, "auth.hmac_secret or auth.jwks_file"},
+		{"trusted callers from anywhere", func(cfg *Config) { cfg.Auth.TrustInternalCallers = true }, "needs server.tls.client_ca_file or auth.trusted_networks"},
+		{"bad trusted network", func(cfg *Config) { cfg.Auth.TrustedNetworks = []string{"10.0.0.1"} }, `"10.0.0.1" isn't a CIDR range`},
+		{"backoff range", func(cfg *Config) { cfg.Lifecycle.RetryMaxBackoff = time.Millisecond }, "lifecycle.retry_max_backoff"},
+		{"unknown log level", func(cfg *Config) { cfg.Logging.Level = "loud" }, "logging.level"},
+	}
+	for _, test := range tests {
+		cfg := validConfig()
+		test.change(&cfg)
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: Validate = %v, want an error mentioning %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestValidateTrustedCallers(t *testing.T) {
+	cfg := validConfig()
+	cfg.Auth.TrustInternalCallers = true
+	cfg.Auth.TrustedNetworks = []string{"10.0.0.0/8", "fd00::/8"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate with trusted networks: %v", err)
+	}
+}
+
+func TestValidateReportsEverything(t *testing.T) {
+	cfg := validConfig()
+	cfg.Mongo.URI = ""
+	cfg.Nats.URL = ""
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "mongo.uri") || !strings.Contains(err.Error(), "nats.url") {
+		t.Errorf("Validate = %v, want both problems", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "mongo:\n  database: from-file\n  operation_timeout: 3s\nauth:\n  hmac_secret: file-secret\n  trusted_networks: [10.0.0.0/8]\n",
+		"config.toml": "[mongo]\ndatabase = \"from-file\"\noperation_timeout = \"3s\"\n[auth]\nhmac_secret = \"file-secret\"\ntrusted_networks = [\"10.0.0.0/8\"]\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: Load: %v", name, err)
+		}
+		if cfg.Mongo.Database != "from-file" || cfg.Mongo.OperationTimeout != 3*time.Second || cfg.Auth.HMACSecret != "file-secret" {
+			t.Errorf("%s: file settings weren't applied: %+v", name, cfg.Mongo)
+		}
+		if !reflect.DeepEqual(cfg.Auth.TrustedNetworks, []string{"10.0.0.0/8"}) {
+			t.Errorf("%s: trusted networks = %v", name, cfg.Auth.TrustedNetworks)
+		}
+		if cfg.Mongo.URI != Default().Mongo.URI {
+			t.Errorf("%s: defaults were lost: %q", name, cfg.Mongo.URI)
+		}
+	}
+}
+
+func TestLoadPrefersTheEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("mongo:\n  database: from-file\nauth:\n  hmac_secret: file-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(FileEnv, path)
+	t.Setenv("MONGO_DATABASE", "from-env")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Mongo.Database != "from-env" || cfg.Auth.HMACSecret != "file-secret" {
+		t.Errorf("database = %q, secret = %q", cfg.Mongo.Database, cfg.Auth.HMACSecret)
+	}
+}
+
+func TestLoadRejectsUnknownFormats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("Load = %v, want an unsupported format error", err)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("MONGO_CONNECT_TIMEOUT", "1m30s")
+	t.Setenv("OTLP_INSECURE", "false")
+	t.Setenv("TRACE_SAMPLE_RATIO", "0.25")
+	t.Setenv("OUTBOX_RELAY_BATCH_SIZE", "50")
+	t.Setenv("AUTH_TRUSTED_NETWORKS", " 10.0.0.0/8, ,192.168.0.0/16 ")
+
+	cfg := Default()
+	if err := applyEnv(&cfg); err != nil {
+		t.Fatalf("applyEnv: %v", err)
+	}
+	if cfg.Mongo.ConnectTimeout != 90*time.Second {
+		t.Errorf("connect timeout = %v", cfg.Mongo.ConnectTimeout)
+	}
+	if cfg.Telemetry.OTLPInsecure || cfg.Telemetry.SampleRatio != 0.25 || cfg.Features.OutboxBatchSize != 50 {
+		t.Errorf("telemetry = %+v, batch size = %d", cfg.Telemetry, cfg.Features.OutboxBatchSize)
+	}
+	if !reflect.DeepEqual(cfg.Auth.TrustedNetworks, []string{"10.0.0.0/8", "192.168.0.0/16"}) {
+		t.Errorf("trusted networks = %q", cfg.Auth.TrustedNetworks)
+	}
+}
+
+func TestApplyEnvNamesTheBadVariable(t *testing.T) {
+	t.Setenv("SHUTDOWN_TIMEOUT", "15")
+	cfg := Default()
+	if err := applyEnv(&cfg); err == nil || !strings.Contains(err.Error(), "SHUTDOWN_TIMEOUT") {
+		t.Errorf("applyEnv = %v, want an error naming SHUTDOWN_TIMEOUT", err)
+	}
+}
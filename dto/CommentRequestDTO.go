@@ -7,4 +7,5 @@ type CommentRequestDTO struct {
 	BlogId    int64     `json:"blogId"`
 	CreatedAt time.Time `json:"createdAt"`
 	Text      string    `json:"text"`
+	ParentId  *int      `json:"parentId,omitempty"`
 }
@@ -0,0 +1,70 @@
+package events
+
+import "time"
+
+const (
+	BlogCreatedSubject       = "blog.created"
+	BlogUpdatedSubject       = "blog.updated"
+	BlogDeletedSubject       = "blog.deleted"
+	BlogBlockedSubject       = "blog.blocked"
+	BlogPublishedSubject     = "blog.published"
+	BlogStatusChangedSubject = "blog.status_changed"
+)
+
+type BlogCreated struct {
+	BlogId    int64     `json:"blog_id"`
+	AuthorId  int64     `json:"author_id"`
+	Title     string    `json:"title"`
+	BlogTopic string    `json:"blog_topic"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (BlogCreated) Subject() string    { return BlogCreatedSubject }
+func (BlogCreated) SchemaVersion() int { return 1 }
+
+// BlogUpdated lists the fields an edit changed.
+type BlogUpdated struct {
+	BlogId   int64    `json:"blog_id"`
+	EditorId int64    `json:"editor_id"`
+	Fields   []string `json:"fields"`
+}
+
+func (BlogUpdated) Subject() string    { return BlogUpdatedSubject }
+func (BlogUpdated) SchemaVersion() int { return 1 }
+
+type BlogDeleted struct {
+	BlogId   int64 `json:"blog_id"`
+	AuthorId int64 `json:"author_id"`
+}
+
+func (BlogDeleted) Subject() string    { return BlogDeletedSubject }
+func (BlogDeleted) SchemaVersion() int { return 1 }
+
+type BlogBlocked struct {
+	BlogId   int64 `json:"blog_id"`
+	AuthorId int64 `json:"author_id"`
+}
+
+func (BlogBlocked) Subject() string    { return BlogBlockedSubject }
+func (BlogBlocked) SchemaVersion() int { return 1 }
+
+type BlogPublished struct {
+	BlogId      int64     `json:"blog_id"`
+	AuthorId    int64     `json:"author_id"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func (BlogPublished) Subject() string    { return BlogPublishedSubject }
+func (BlogPublished) SchemaVersion() int { return 1 }
+
+// BlogStatusChanged reports any change of a blog's status, whether made by
+// its author or derived from its votes and comments.
+type BlogStatusChanged struct {
+	BlogId int64  `json:"blog_id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+func (BlogStatusChanged) Subject() string    { return BlogStatusChangedSubject }
+func (BlogStatusChanged) SchemaVersion() int { return 1 }
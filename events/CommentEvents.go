@@ -0,0 +1,25 @@
+package events
+
+const (
+	CommentCreatedSubject = "comment.created"
+	// CommentCreationCompensatedSubject acknowledges a saga rollback.
+	CommentCreationCompensatedSubject = "comment.creation.compensated"
+)
+
+// CommentCreated starts the comment-creation saga. UserId is the commenter
+// and AuthorId the author of the blog commented on.
+type CommentCreated struct {
+	UserId    int64 `json:"user_id"`
+	AuthorId  int64 `json:"author_id"`
+	CommentId int   `json:"comment_id"`
+}
+
+func (CommentCreated) Subject() string    { return CommentCreatedSubject }
+func (CommentCreated) SchemaVersion() int { return 1 }
+
+type CommentCreationCompensated struct {
+	CommentId int64 `json:"comment_id"`
+}
+
+func (CommentCreationCompensated) Subject() string    { return CommentCreationCompensatedSubject }
+func (CommentCreationCompensated) SchemaVersion() int { return 1 }
@@ -0,0 +1,173 @@
+package events
+
+import (
+	"BlogApplication/logging"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	DefaultMaxDeliver = 5
+	DefaultAckWait    = 30 * time.Second
+	DefaultRetryDelay = time.Second
+	// DefaultStreamMaxAge bounds how long unconsumed messages are kept.
+	DefaultStreamMaxAge = 7 * 24 * time.Hour
+)
+
+// Handler processes one message. Returning an error asks for the message to
+// be redelivered, unless the error is wrapped with Permanent.
+type Handler func(ctx context.Context, msg jetstream.Msg) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as a failure redelivery won't fix, such as a malformed
+// message, which sends the message straight to the dead-letter queue.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// DurableConsumer feeds the messages of a JetStream stream to a Handler
+// through a durable pull consumer, so messages published while the service
+// is down are handled once it's back. Messages are acked explicitly after
+// the handler succeeds. A failed message is redelivered after RetryDelay,
+// doubling with each attempt, and dead-lettered after MaxDeliver attempts.
+type DurableConsumer struct {
+	JetStream   jetstream.JetStream
+	Stream      string
+	Subjects    []string
+	Durable     string
+	MaxDeliver  int
+	AckWait     time.Duration
+	RetryDelay  time.Duration
+	DeadLetters *DeadLetterQueue
+	Handler     Handler
+	Logger      *slog.Logger
+}
+
+// Start creates or updates the stream and the consumer and starts consuming
+// in the background. Stop the returned context to stop consuming.
+func (c *DurableConsumer) Start(ctx context.Context) (jetstream.ConsumeContext, error) {
+	stream, err := c.JetStream.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     c.Stream,
+		Subjects: c.Subjects,
+		Storage:  jetstream.FileStorage,
+		MaxAge:   DefaultStreamMaxAge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating stream %s: %w", c.Stream, err)
+	}
+
+	// The server redelivers without limit; the consumer enforces MaxDeliver
+	// itself so a message is only dropped once it's safely dead-lettered.
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:        c.Durable,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        c.ackWait(),
+		MaxDeliver:     -1,
+		FilterSubjects: c.Subjects,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating consumer %s: %w", c.Durable, err)
+	}
+
+	return consumer.Consume(c.handle, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		c.logger().Error("Consumer failed", "error", err)
+	}))
+}
+
+func (c *DurableConsumer) handle(msg jetstream.Msg) {
+	// The handler's span continues the publisher's trace, so a message's
+	// whole journey shows up as one trace.
+	ctx := ExtractTraceContext(context.Background(), msg.Headers())
+	tracer := otel.Tracer("events")
+	ctx, span := tracer.Start(ctx, msg.Subject()+" process", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.destination.name", msg.Subject()),
+		attribute.String("messaging.consumer.group.name", c.Durable),
+	)
+
+	err := c.Handler(ctx, msg)
+	if err == nil {
+		span.SetStatus(codes.Ok, "Handle successful")
+		if ackErr := msg.Ack(); ackErr != nil {
+			c.logger().ErrorContext(ctx, "Failed to ack message", "messaging.destination.name", msg.Subject(), "error", ackErr)
+		}
+		return
+	}
+
+	delivered := uint64(1)
+	if metadata, metaErr := msg.Metadata(); metaErr == nil {
+		delivered = metadata.NumDelivered
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "Handle failed")
+
+	var permanent *permanentError
+	if !errors.As(err, &permanent) && delivered < uint64(c.maxDeliver()) {
+		c.logger().WarnContext(ctx, "Message handling failed, will retry", "messaging.destination.name", msg.Subject(), "attempt", delivered, "error", err)
+		if nakErr := msg.NakWithDelay(c.retryDelay(delivered)); nakErr != nil {
+			c.logger().ErrorContext(ctx, "Failed to nak message", "messaging.destination.name", msg.Subject(), "error", nakErr)
+		}
+		return
+	}
+
+	dlqErr := c.DeadLetters.Send(ctx, msg, c.Durable, err, delivered)
+	if dlqErr != nil {
+		c.logger().ErrorContext(ctx, "Failed to dead-letter message", "messaging.destination.name", msg.Subject(), "error", dlqErr)
+		_ = msg.NakWithDelay(c.retryDelay(delivered))
+		return
+	}
+	c.logger().ErrorContext(ctx, "Message dead-lettered", "messaging.destination.name", msg.Subject(), "attempts", delivered, "error", err)
+	if termErr := msg.Term(); termErr != nil {
+		c.logger().ErrorContext(ctx, "Failed to terminate message", "messaging.destination.name", msg.Subject(), "error", termErr)
+	}
+}
+
+func (c *DurableConsumer) logger() *slog.Logger {
+	return logging.OrDefault(c.Logger).With("messaging.consumer.group.name", c.Durable)
+}
+
+func (c *DurableConsumer) maxDeliver() int {
+	if c.MaxDeliver > 0 {
+		return c.MaxDeliver
+	}
+	return DefaultMaxDeliver
+}
+
+func (c *DurableConsumer) ackWait() time.Duration {
+	if c.AckWait > 0 {
+		return c.AckWait
+	}
+	return DefaultAckWait
+}
+
+// retryDelay is how long to wait before redelivering a message that failed
+// on its delivered-th attempt.
+func (c *DurableConsumer) retryDelay(delivered uint64) time.Duration {
+	delay := c.RetryDelay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+	for i := uint64(1); i < delivered && delay < c.ackWait(); i++ {
+		delay *= 2
+	}
+	return min(delay, c.ackWait())
+}
@@ -0,0 +1,194 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func startTestConsumer(t *testing.T, js jetstream.JetStream, handler Handler) *DeadLetterQueue {
+	t.Helper()
+	ctx := context.Background()
+	deadLetters := &DeadLetterQueue{JetStream: js, Stream: "DEAD_LETTERS", SubjectPrefix: "dlq"}
+	if err := deadLetters.EnsureStream(ctx); err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	consumer := &DurableConsumer{
+		JetStream:   js,
+		Stream:      "BLOGS",
+		Subjects:    []string{"blog.>"},
+		Durable:     "test",
+		MaxDeliver:  3,
+		AckWait:     time.Second,
+		RetryDelay:  10 * time.Millisecond,
+		DeadLetters: deadLetters,
+		Handler:     handler,
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	consumed, err := consumer.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(consumed.Stop)
+	return deadLetters
+}
+
+func publish(t *testing.T, js jetstream.JetStream, subject string, data string) {
+	t.Helper()
+	msg := nats.NewMsg(subject)
+	msg.Header.Set("Trace", "kept")
+	msg.Data = []byte(data)
+	if _, err := js.PublishMsg(context.Background(), msg); err != nil {
+		t.Fatalf("publishing %s: %v", subject, err)
+	}
+}
+
+// waitForDeadLetters polls the queue until it holds want dead letters.
+func waitForDeadLetters(t *testing.T, queue *DeadLetterQueue, want int) []DeadLetter {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deadLetters, _, err := queue.List(context.Background(), 0, 10)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(deadLetters) == want {
+			return deadLetters
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d dead letters, want %d", len(deadLetters), want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestConsumerDeadLettersAfterMaxDeliver(t *testing.T) {
+	js := testJetStream(t)
+	var attempts atomic.Int32
+	queue := startTestConsumer(t, js, func(ctx context.Context, msg jetstream.Msg) error {
+		attempts.Add(1)
+		return errors.New("handler failed")
+	})
+
+	publish(t, js, "blog.created", `{"blogId":1}`)
+
+	deadLetters := waitForDeadLetters(t, queue, 1)
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("handler ran %d times, want 3", got)
+	}
+	deadLetter := deadLetters[0]
+	if deadLetter.Subject != "blog.created" || deadLetter.Consumer != "test" || deadLetter.Deliveries != 3 {
+		t.Errorf("dead letter = %+v", deadLetter)
+	}
+	if deadLetter.Error != "handler failed" {
+		t.Errorf("Error = %q, want %q", deadLetter.Error, "handler failed")
+	}
+	if string(deadLetter.Data) != `{"blogId":1}` {
+		t.Errorf("Data = %s", deadLetter.Data)
+	}
+
+	// Once dead-lettered, the message isn't redelivered.
+	time.Sleep(200 * time.Millisecond)
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("handler ran %d times after dead-lettering, want 3", got)
+	}
+}
+
+func TestConsumerDeadLettersPermanentErrorsAtOnce(t *testing.T) {
+	js := testJetStream(t)
+	var attempts atomic.Int32
+	queue := startTestConsumer(t, js, func(ctx context.Context, msg jetstream.Msg) error {
+		attempts.Add(1)
+		return Permanent(errors.New("malformed message"))
+	})
+
+	publish(t, js, "blog.created", "not json")
+
+	deadLetters := waitForDeadLetters(t, queue, 1)
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+	if deadLetters[0].Deliveries != 1 {
+		t.Errorf("Deliveries = %d, want 1", deadLetters[0].Deliveries)
+	}
+}
+
+func TestDeadLetterListPages(t *testing.T) {
+	js := testJetStream(t)
+	queue := startTestConsumer(t, js, func(ctx context.Context, msg jetstream.Msg) error {
+		return Permanent(errors.New("rejected"))
+	})
+
+	for _, subject := range []string{"blog.created", "blog.updated", "blog.deleted"} {
+		publish(t, js, subject, subject)
+	}
+	waitForDeadLetters(t, queue, 3)
+
+	first, next, err := queue.List(context.Background(), 0, 2)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(first) != 2 || next == 0 {
+		t.Fatalf("first page = %d dead letters, next %d", len(first), next)
+	}
+	rest, next, err := queue.List(context.Background(), next, 2)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(rest) != 1 || next != 0 {
+		t.Fatalf("second page = %d dead letters, next %d", len(rest), next)
+	}
+	if first[0].Sequence >= first[1].Sequence || first[1].Sequence >= rest[0].Sequence {
+		t.Errorf("dead letters aren't oldest first: %d, %d, %d", first[0].Sequence, first[1].Sequence, rest[0].Sequence)
+	}
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	js := testJetStream(t)
+	var healthy atomic.Bool
+	handled := make(chan jetstream.Msg, 1)
+	queue := startTestConsumer(t, js, func(ctx context.Context, msg jetstream.Msg) error {
+		if !healthy.Load() {
+			return Permanent(errors.New("downstream unavailable"))
+		}
+		handled <- msg
+		return nil
+	})
+
+	publish(t, js, "blog.created", `{"blogId":1}`)
+	deadLetters := waitForDeadLetters(t, queue, 1)
+
+	healthy.Store(true)
+	if err := queue.Replay(context.Background(), deadLetters[0].Sequence); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	select {
+	case msg := <-handled:
+		if msg.Subject() != "blog.created" || string(msg.Data()) != `{"blogId":1}` {
+			t.Errorf("replayed %s %s", msg.Subject(), msg.Data())
+		}
+		if msg.Headers().Get("Trace") != "kept" {
+			t.Error("replay dropped the original headers")
+		}
+		for _, key := range deadLetterHeaders {
+			if msg.Headers().Get(key) != "" {
+				t.Errorf("replay kept header %s", key)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("replayed message wasn't handled")
+	}
+
+	waitForDeadLetters(t, queue, 0)
+	if err := queue.Replay(context.Background(), deadLetters[0].Sequence); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Errorf("second Replay = %v, want ErrDeadLetterNotFound", err)
+	}
+}
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"BlogApplication/telemetry"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Headers a dead letter carries besides those of the original message.
+const (
+	HeaderDeadLetterSubject    = "Dead-Letter-Subject"
+	HeaderDeadLetterConsumer   = "Dead-Letter-Consumer"
+	HeaderDeadLetterError      = "Dead-Letter-Error"
+	HeaderDeadLetterDeliveries = "Dead-Letter-Deliveries"
+	HeaderDeadLetterFailedAt   = "Dead-Letter-Failed-At"
+)
+
+var deadLetterHeaders = []string{
+	HeaderDeadLetterSubject,
+	HeaderDeadLetterConsumer,
+	HeaderDeadLetterError,
+	HeaderDeadLetterDeliveries,
+	HeaderDeadLetterFailedAt,
+}
+
+// ErrDeadLetterNotFound is returned for a sequence with no dead letter.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a message no consumer could handle.
+type DeadLetter struct {
+	Sequence   uint64
+	Subject    string
+	Consumer   string
+	Error      string
+	Deliveries int
+	FailedAt   time.Time
+	Data       []byte
+	Header     nats.Header
+}
+
+// DeadLetterQueue keeps messages that failed every delivery attempt in a
+// JetStream stream under SubjectPrefix, until they're replayed.
+type DeadLetterQueue struct {
+	JetStream     jetstream.JetStream
+	Stream        string
+	SubjectPrefix string
+}
+
+func (queue *DeadLetterQueue) EnsureStream(ctx context.Context) error {
+	_, err := queue.JetStream.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     queue.Stream,
+		Subjects: []string{queue.SubjectPrefix + ".>"},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating dead-letter stream %s: %w", queue.Stream, err)
+	}
+	return nil
+}
+
+// Send stores msg as a dead letter, recording why and after how many
+// deliveries it failed.
+func (queue *DeadLetterQueue) Send(ctx context.Context, msg jetstream.Msg, consumer string, reason error, deliveries uint64) error {
+	deadLetter := nats.NewMsg(queue.SubjectPrefix + "." + msg.Subject())
+	for key, values := range msg.Headers() {
+		deadLetter.Header[key] = values
+	}
+	// A dead letter is never a duplicate, even of one replayed earlier.
+	deadLetter.Header.Del(HeaderMsgId)
+	deadLetter.Header.Set(HeaderDeadLetterSubject, msg.Subject())
+	deadLetter.Header.Set(HeaderDeadLetterConsumer, consumer)
+	deadLetter.Header.Set(HeaderDeadLetterError, reason.Error())
+	deadLetter.Header.Set(HeaderDeadLetterDeliveries, strconv.FormatUint(deliveries, 10))
+	deadLetter.Header.Set(HeaderDeadLetterFailedAt, time.Now().UTC().Format(time.RFC3339Nano))
+	deadLetter.Data = msg.Data()
+
+	if _, err := queue.JetStream.PublishMsg(ctx, deadLetter); err != nil {
+		telemetry.PublishFailed(ctx, deadLetter.Subject)
+		return err
+	}
+	return nil
+}
+
+// List returns up to limit dead letters, oldest first, starting at sequence
+// from, and the sequence to continue from, or zero after the last one.
+func (queue *DeadLetterQueue) List(ctx context.Context, from uint64, limit int) ([]DeadLetter, uint64, error) {
+	stream, err := queue.JetStream.Stream(ctx, queue.Stream)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	deadLetters := make([]DeadLetter, 0)
+	seq := max(from, 1)
+	for len(deadLetters) < limit {
+		raw, err := stream.GetMsg(ctx, seq, jetstream.WithGetMsgSubject(queue.SubjectPrefix+".>"))
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			return deadLetters, 0, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		deadLetters = append(deadLetters, toDeadLetter(raw))
+		seq = raw.Sequence + 1
+	}
+	return deadLetters, seq, nil
+}
+
+// Replay publishes a dead letter again on its original subject, without the
+// dead-letter headers, and removes it from the queue.
+func (queue *DeadLetterQueue) Replay(ctx context.Context, seq uint64) error {
+	stream, err := queue.JetStream.Stream(ctx, queue.Stream)
+	if err != nil {
+		return err
+	}
+	raw, err := stream.GetMsg(ctx, seq)
+	if errors.Is(err, jetstream.ErrMsgNotFound) {
+		return ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	deadLetter := toDeadLetter(raw)
+	msg := nats.NewMsg(deadLetter.Subject)
+	for key, values := range raw.Header {
+		msg.Header[key] = values
+	}
+	for _, key := range deadLetterHeaders {
+		msg.Header.Del(key)
+	}
+	msg.Data = raw.Data
+
+	if _, err := queue.JetStream.PublishMsg(ctx, msg); err != nil {
+		telemetry.PublishFailed(ctx, msg.Subject)
+		return fmt.Errorf("error replaying dead letter %d: %w", seq, err)
+	}
+	return stream.DeleteMsg(ctx, seq)
+}
+
+func toDeadLetter(raw *jetstream.RawStreamMsg) DeadLetter {
+	deliveries, _ := strconv.Atoi(raw.Header.Get(HeaderDeadLetterDeliveries))
+	failedAt, err := time.Parse(time.RFC3339Nano, raw.Header.Get(HeaderDeadLetterFailedAt))
+	if err != nil {
+		failedAt = raw.Time
+	}
+	return DeadLetter{
+		Sequence:   raw.Sequence,
+		Subject:    raw.Header.Get(HeaderDeadLetterSubject),
+		Consumer:   raw.Header.Get(HeaderDeadLetterConsumer),
+		Error:      raw.Header.Get(HeaderDeadLetterError),
+		Deliveries: deliveries,
+		FailedAt:   failedAt,
+		Data:       raw.Data,
+		Header:     raw.Header,
+	}
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers carrying an event's metadata. The message body is the event's own
+// JSON, so consumers that predate the headers keep working.
+const (
+	HeaderEventId      = "Event-Id"
+	HeaderEventType    = "Event-Type"
+	HeaderEventVersion = "Event-Version"
+	HeaderOccurredAt   = "Event-Occurred-At"
+	// HeaderMsgId lets JetStream drop duplicates of the same event.
+	HeaderMsgId = "Nats-Msg-Id"
+)
+
+// Event is a domain event. Its subject doubles as its type, and its schema
+// version changes whenever its JSON changes incompatibly.
+type Event interface {
+	Subject() string
+	SchemaVersion() int
+}
+
+// Message is an encoded event ready to publish.
+type Message struct {
+	Id         string
+	Subject    string
+	Version    int
+	OccurredAt time.Time
+	Data       []byte
+	// TraceContext holds the propagation headers of the trace that raised
+	// the event, captured when it was raised rather than when it's sent.
+	TraceContext map[string]string
+}
+
+// Encode turns an event into a message with the given unique id.
+func Encode(id string, event Event, occurredAt time.Time) (*Message, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %s event: %w", event.Subject(), err)
+	}
+	return &Message{
+		Id:         id,
+		Subject:    event.Subject(),
+		Version:    event.SchemaVersion(),
+		OccurredAt: occurredAt,
+		Data:       data,
+	}, nil
+}
+
+// Headers returns the message's metadata and trace context headers.
+func (m *Message) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderEventId:      m.Id,
+		HeaderEventType:    m.Subject,
+		HeaderEventVersion: strconv.Itoa(m.Version),
+		HeaderOccurredAt:   m.OccurredAt.UTC().Format(time.RFC3339Nano),
+		HeaderMsgId:        m.Id,
+	}
+	for key, value := range m.TraceContext {
+		headers[key] = value
+	}
+	return headers
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// allEvents lists one of every event the service raises.
+var allEvents = []Event{
+	BlogCreated{}, BlogUpdated{}, BlogDeleted{}, BlogBlocked{}, BlogPublished{}, BlogStatusChanged{},
+	CommentCreated{}, CommentCreationCompensated{},
+	VoteCast{}, ReportCreated{},
+}
+
+func TestEventSubjectsAndVersions(t *testing.T) {
+	subjects := map[string]bool{}
+	for _, event := range allEvents {
+		if subjects[event.Subject()] {
+			t.Errorf("%T reuses subject %s", event, event.Subject())
+		}
+		subjects[event.Subject()] = true
+		if event.SchemaVersion() < 1 {
+			t.Errorf("%T has schema version %d", event, event.SchemaVersion())
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	event := VoteCast{BlogId: 1, UserId: 2, VoteType: "UPVOTE"}
+	occurredAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	message, err := Encode("event-1", event, occurredAt)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if message.Subject != VoteCastSubject || message.Version != 1 {
+		t.Errorf("message is %s v%d", message.Subject, message.Version)
+	}
+	// The body is the event's own JSON, so consumers need no envelope.
+	var decoded VoteCast
+	if err := json.Unmarshal(message.Data, &decoded); err != nil || decoded != event {
+		t.Errorf("body decodes to %+v, %v", decoded, err)
+	}
+
+	message.TraceContext = map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+	want := map[string]string{
+		HeaderEventId:      "event-1",
+		HeaderEventType:    VoteCastSubject,
+		HeaderEventVersion: "1",
+		HeaderOccurredAt:   "2024-05-01T10:00:00Z",
+		HeaderMsgId:        "event-1",
+		"traceparent":      "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+	if headers := message.Headers(); !reflect.DeepEqual(headers, want) {
+		t.Errorf("Headers = %v, want %v", headers, want)
+	}
+}
+
+func TestNatsPublisherSendsHeaders(t *testing.T) {
+	conn := testNats(t)
+	sub, err := conn.SubscribeSync(BlogDeletedSubject)
+	if err != nil {
+		t.Fatalf("SubscribeSync: %v", err)
+	}
+
+	message, err := Encode("event-2", BlogDeleted{BlogId: 1, AuthorId: 2}, time.Now())
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	publisher := &NatsPublisher{Conn: conn}
+	ctx := context.Background()
+	if err := publisher.Publish(ctx, message); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := publisher.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	msg, err := sub.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatalf("NextMsg: %v", err)
+	}
+	if string(msg.Data) != string(message.Data) {
+		t.Errorf("body = %s, want %s", msg.Data, message.Data)
+	}
+	for key, value := range message.Headers() {
+		if got := msg.Header.Get(key); got != value {
+			t.Errorf("header %s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"BlogApplication/telemetry"
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+)
+
+// Publisher sends encoded events to the message broker.
+type Publisher interface {
+	// Publish queues a message for sending.
+	Publish(ctx context.Context, message *Message) error
+	// Flush returns once every queued message has reached the broker.
+	Flush(ctx context.Context) error
+}
+
+const DefaultFlushTimeout = 5 * time.Second
+
+// NatsPublisher publishes events on core NATS, metadata in headers.
+type NatsPublisher struct {
+	Conn *nats.Conn
+	// FlushTimeout bounds Flush when ctx has no deadline.
+	FlushTimeout time.Duration
+}
+
+// Publish sends the message with the trace context it was raised in, or
+// ctx's if it doesn't carry one.
+func (publisher *NatsPublisher) Publish(ctx context.Context, message *Message) error {
+	msg := nats.NewMsg(message.Subject)
+	if message.TraceContext == nil {
+		otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Header))
+	}
+	for key, value := range message.Headers() {
+		msg.Header.Set(key, value)
+	}
+	msg.Data = message.Data
+	if err := publisher.Conn.PublishMsg(msg); err != nil {
+		telemetry.PublishFailed(ctx, message.Subject)
+		return err
+	}
+	return nil
+}
+
+func (publisher *NatsPublisher) Flush(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := publisher.FlushTimeout
+		if timeout <= 0 {
+			timeout = DefaultFlushTimeout
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return publisher.Conn.FlushWithContext(ctx)
+}
@@ -0,0 +1,13 @@
+package events
+
+const ReportCreatedSubject = "report.created"
+
+type ReportCreated struct {
+	ReportId int    `json:"report_id"`
+	BlogId   int    `json:"blog_id"`
+	UserId   int    `json:"user_id"`
+	Reason   string `json:"reason"`
+}
+
+func (ReportCreated) Subject() string    { return ReportCreatedSubject }
+func (ReportCreated) SchemaVersion() int { return 1 }
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// TraceContext returns the headers that carry ctx's trace to whoever
+// consumes a message published on its behalf.
+func TraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// ExtractTraceContext returns ctx joined to the trace the message's
+// publisher was in, if its headers carry one.
+func ExtractTraceContext(ctx context.Context, header nats.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier(header))
+}
+
+// headerCarrier adapts NATS headers to the propagation API. Unlike HTTP
+// headers they're case-sensitive, and propagators use lower case keys.
+type headerCarrier nats.Header
+
+func (carrier headerCarrier) Get(key string) string {
+	return nats.Header(carrier).Get(key)
+}
+
+func (carrier headerCarrier) Set(key string, value string) {
+	nats.Header(carrier).Set(key, value)
+}
+
+func (carrier headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// withTraceContextPropagator installs the W3C propagator main.go uses for
+// the rest of the test.
+func withTraceContextPropagator(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+}
+
+func testSpanContext(spanID byte) trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0a, 0xf7, 0x65},
+		SpanID:     trace.SpanID{spanID},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestTraceContextIsEmptyWithoutATrace(t *testing.T) {
+	withTraceContextPropagator(t)
+	if headers := TraceContext(context.Background()); headers != nil {
+		t.Errorf("TraceContext = %v, want nil", headers)
+	}
+}
+
+// TestTraceContextSurvivesPublishing checks that consumers join the trace
+// an event was raised in, not the one it happened to be sent from.
+func TestTraceContextSurvivesPublishing(t *testing.T) {
+	withTraceContextPropagator(t)
+	conn := testNats(t)
+	sub, err := conn.SubscribeSync(BlogCreatedSubject)
+	if err != nil {
+		t.Fatalf("SubscribeSync: %v", err)
+	}
+	publisher := &NatsPublisher{Conn: conn}
+
+	raisedIn := testSpanContext(1)
+	sentFrom := testSpanContext(2)
+	message, err := Encode("event-1", BlogCreated{BlogId: 1}, time.Now())
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	message.TraceContext = TraceContext(trace.ContextWithSpanContext(context.Background(), raisedIn))
+
+	sendCtx := trace.ContextWithSpanContext(context.Background(), sentFrom)
+	if err := publisher.Publish(sendCtx, message); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	// A message without a captured trace context carries the sender's.
+	message, err = Encode("event-2", BlogCreated{BlogId: 2}, time.Now())
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := publisher.Publish(sendCtx, message); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	for _, want := range []trace.SpanContext{raisedIn, sentFrom} {
+		msg, err := sub.NextMsg(5 * time.Second)
+		if err != nil {
+			t.Fatalf("NextMsg: %v", err)
+		}
+		got := trace.SpanContextFromContext(ExtractTraceContext(context.Background(), msg.Header))
+		if !got.IsRemote() || got.TraceID() != want.TraceID() || got.SpanID() != want.SpanID() {
+			t.Errorf("message %s joined %v, want %v", msg.Header.Get(HeaderEventId), got, want)
+		}
+	}
+}
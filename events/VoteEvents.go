@@ -0,0 +1,15 @@
+package events
+
+const VoteCastSubject = "vote.cast"
+
+// VoteCast reports a new or changed vote. PreviousVoteType is empty for a
+// user's first vote on the blog.
+type VoteCast struct {
+	BlogId           int64  `json:"blog_id"`
+	UserId           int64  `json:"user_id"`
+	VoteType         string `json:"vote_type"`
+	PreviousVoteType string `json:"previous_vote_type,omitempty"`
+}
+
+func (VoteCast) Subject() string    { return VoteCastSubject }
+func (VoteCast) SchemaVersion() int { return 1 }
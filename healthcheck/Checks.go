@@ -0,0 +1,35 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// MongoCheck fails while the primary can't be reached, since every write
+// goes there.
+func MongoCheck(client *mongo.Client) Check {
+	return Check{
+		Name: "mongodb",
+		Probe: func(ctx context.Context) error {
+			return client.Ping(ctx, readpref.Primary())
+		},
+	}
+}
+
+// NatsCheck fails while the connection isn't established, including while
+// it's reconnecting.
+func NatsCheck(conn *nats.Conn) Check {
+	return Check{
+		Name: "nats",
+		Probe: func(ctx context.Context) error {
+			if status := conn.Status(); status != nats.CONNECTED {
+				return fmt.Errorf("connection is %s", status)
+			}
+			return nil
+		},
+	}
+}
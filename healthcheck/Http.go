@@ -0,0 +1,44 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Handler serves /healthz, which succeeds as long as the process can answer,
+// and /readyz, which fails with 503 while a dependency is down.
+func Handler(monitor *Monitor) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready, failures := monitor.Ready()
+
+		response := readiness{Status: "ready", Checks: make(map[string]string, len(monitor.Checks))}
+		for _, check := range monitor.Checks {
+			response.Checks[check.Name] = "ok"
+			if failures == nil {
+				response.Checks[check.Name] = "unknown"
+			} else if err, failed := failures[check.Name]; failed {
+				response.Checks[check.Name] = err.Error()
+			}
+		}
+		status := http.StatusOK
+		if !ready {
+			response.Status = "not ready"
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	})
+	return mux
+}
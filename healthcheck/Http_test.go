@@ -0,0 +1,61 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/health"
+)
+
+func TestHandler(t *testing.T) {
+	natsErr := errors.New("connection is CLOSED")
+	monitor := &Monitor{
+		Health: health.NewServer(),
+		Checks: []Check{
+			{Name: "mongodb", Probe: func(ctx context.Context) error { return nil }},
+			{Name: "nats", Probe: func(ctx context.Context) error { return natsErr }},
+		},
+	}
+	handler := Handler(monitor)
+	get := func(path string) (int, readiness) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var body readiness
+		if path == "/readyz" {
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding %s: %v", path, err)
+			}
+		}
+		return recorder.Code, body
+	}
+
+	if code, _ := get("/healthz"); code != http.StatusOK {
+		t.Errorf("/healthz = %d", code)
+	}
+
+	code, body := get("/readyz")
+	want := readiness{Status: "not ready", Checks: map[string]string{"mongodb": "unknown", "nats": "unknown"}}
+	if code != http.StatusServiceUnavailable || !reflect.DeepEqual(body, want) {
+		t.Errorf("/readyz before any check = %d %+v", code, body)
+	}
+
+	monitor.CheckNow(context.Background())
+	code, body = get("/readyz")
+	want = readiness{Status: "not ready", Checks: map[string]string{"mongodb": "ok", "nats": "connection is CLOSED"}}
+	if code != http.StatusServiceUnavailable || !reflect.DeepEqual(body, want) {
+		t.Errorf("/readyz with nats down = %d %+v", code, body)
+	}
+
+	natsErr = nil
+	monitor.CheckNow(context.Background())
+	code, body = get("/readyz")
+	want = readiness{Status: "ready", Checks: map[string]string{"mongodb": "ok", "nats": "ok"}}
+	if code != http.StatusOK || !reflect.DeepEqual(body, want) {
+		t.Errorf("/readyz when ready = %d %+v", code, body)
+	}
+}
@@ -0,0 +1,150 @@
+package healthcheck
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	DefaultCheckInterval = 5 * time.Second
+	DefaultCheckTimeout  = 2 * time.Second
+)
+
+// Check probes one dependency. Probe returns nil while the dependency is
+// usable.
+type Check struct {
+	Name  string
+	Probe func(ctx context.Context) error
+}
+
+// Monitor probes the service's dependencies and publishes the results on the
+// gRPC health server. Each check is reported under its own name, and the
+// overall status ("") and every name in Services are SERVING only while all
+// checks pass.
+type Monitor struct {
+	Health   *health.Server
+	Services []string
+	Checks   []Check
+	Interval time.Duration
+	Timeout  time.Duration
+
+	mu       sync.RWMutex
+	failures map[string]error
+	stopped  bool
+
+	refresh     chan struct{}
+	refreshOnce sync.Once
+}
+
+// Refresh asks the monitor to probe now rather than at its next tick, e.g.
+// when a connection reports it dropped.
+func (monitor *Monitor) Refresh() {
+	select {
+	case monitor.refreshChannel() <- struct{}{}:
+	default:
+	}
+}
+
+func (monitor *Monitor) refreshChannel() chan struct{} {
+	monitor.refreshOnce.Do(func() {
+		monitor.refresh = make(chan struct{}, 1)
+	})
+	return monitor.refresh
+}
+
+// Run probes every Interval, or sooner when refreshed, until ctx is done.
+func (monitor *Monitor) Run(ctx context.Context) {
+	refresh := monitor.refreshChannel()
+	ticker := time.NewTicker(monitor.interval())
+	defer ticker.Stop()
+
+	monitor.CheckNow(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh:
+		case <-ticker.C:
+		}
+		monitor.CheckNow(ctx)
+	}
+}
+
+// CheckNow runs every check once and updates the published statuses.
+func (monitor *Monitor) CheckNow(ctx context.Context) {
+	failures := make(map[string]error, len(monitor.Checks))
+	for _, check := range monitor.Checks {
+		probeCtx, cancel := context.WithTimeout(ctx, monitor.timeout())
+		if err := check.Probe(probeCtx); err != nil {
+			failures[check.Name] = err
+		}
+		cancel()
+	}
+
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	if monitor.stopped {
+		return
+	}
+	for _, check := range monitor.Checks {
+		err, failed := failures[check.Name]
+		previous, wasFailed := monitor.failures[check.Name]
+		if failed && !wasFailed {
+			slog.WarnContext(ctx, "Dependency is unavailable", "dependency", check.Name, "error", err)
+		} else if !failed && wasFailed {
+			slog.InfoContext(ctx, "Dependency recovered", "dependency", check.Name, "last_error", previous)
+		}
+		monitor.Health.SetServingStatus(check.Name, servingStatus(!failed))
+	}
+
+	overall := servingStatus(len(failures) == 0)
+	monitor.Health.SetServingStatus("", overall)
+	for _, service := range monitor.Services {
+		monitor.Health.SetServingStatus(service, overall)
+	}
+	monitor.failures = failures
+}
+
+// Ready reports whether every dependency passed its last check, and the
+// error of each one that didn't. It's false before the first check and
+// after Stop.
+func (monitor *Monitor) Ready() (bool, map[string]error) {
+	monitor.mu.RLock()
+	defer monitor.mu.RUnlock()
+	return monitor.failures != nil && len(monitor.failures) == 0 && !monitor.stopped, monitor.failures
+}
+
+// Stop reports every service as NOT_SERVING for good, so load balancers stop
+// routing here before the server goes away.
+func (monitor *Monitor) Stop() {
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	monitor.stopped = true
+	monitor.Health.Shutdown()
+}
+
+func (monitor *Monitor) interval() time.Duration {
+	if monitor.Interval > 0 {
+		return monitor.Interval
+	}
+	return DefaultCheckInterval
+}
+
+func (monitor *Monitor) timeout() time.Duration {
+	if monitor.Timeout > 0 {
+		return monitor.Timeout
+	}
+	return DefaultCheckTimeout
+}
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
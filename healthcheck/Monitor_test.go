@@ -0,0 +1,107 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// switchableCheck fails while its err is set.
+func switchableCheck(name string, err *error) Check {
+	return Check{Name: name, Probe: func(ctx context.Context) error { return *err }}
+}
+
+func servingStatusOf(t *testing.T, server *health.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	response, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q): %v", service, err)
+	}
+	return response.Status
+}
+
+func TestMonitorPublishesEveryCheck(t *testing.T) {
+	var mongoErr, natsErr error
+	monitor := &Monitor{
+		Health:   health.NewServer(),
+		Services: []string{"BlogMicroservice"},
+		Checks:   []Check{switchableCheck("mongodb", &mongoErr), switchableCheck("nats", &natsErr)},
+	}
+	if ready, _ := monitor.Ready(); ready {
+		t.Error("ready before the first check")
+	}
+
+	ctx := context.Background()
+	monitor.CheckNow(ctx)
+	if ready, failures := monitor.Ready(); !ready || len(failures) != 0 {
+		t.Errorf("Ready = %v, %v after passing checks", ready, failures)
+	}
+	for _, service := range []string{"", "BlogMicroservice", "mongodb", "nats"} {
+		if status := servingStatusOf(t, monitor.Health, service); status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("%q is %s, want SERVING", service, status)
+		}
+	}
+
+	natsErr = errors.New("connection is RECONNECTING")
+	monitor.CheckNow(ctx)
+	ready, failures := monitor.Ready()
+	if ready || failures["nats"] != natsErr || len(failures) != 1 {
+		t.Errorf("Ready = %v, %v with nats down", ready, failures)
+	}
+	want := map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":                 healthpb.HealthCheckResponse_NOT_SERVING,
+		"BlogMicroservice": healthpb.HealthCheckResponse_NOT_SERVING,
+		"mongodb":          healthpb.HealthCheckResponse_SERVING,
+		"nats":             healthpb.HealthCheckResponse_NOT_SERVING,
+	}
+	for service, status := range want {
+		if got := servingStatusOf(t, monitor.Health, service); got != status {
+			t.Errorf("%q is %s with nats down, want %s", service, got, status)
+		}
+	}
+
+	natsErr = nil
+	monitor.CheckNow(ctx)
+	if ready, _ := monitor.Ready(); !ready {
+		t.Error("not ready after nats recovered")
+	}
+}
+
+func TestMonitorStopIsFinal(t *testing.T) {
+	monitor := &Monitor{Health: health.NewServer(), Checks: []Check{{Name: "mongodb", Probe: func(ctx context.Context) error { return nil }}}}
+	ctx := context.Background()
+	monitor.CheckNow(ctx)
+
+	monitor.Stop()
+	monitor.CheckNow(ctx)
+	if ready, _ := monitor.Ready(); ready {
+		t.Error("ready after Stop")
+	}
+	if status := servingStatusOf(t, monitor.Health, ""); status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("overall status after Stop is %s", status)
+	}
+}
+
+func TestMonitorRunChecksOnRefresh(t *testing.T) {
+	probed := make(chan struct{}, 10)
+	monitor := &Monitor{
+		Health: health.NewServer(),
+		Checks: []Check{{Name: "nats", Probe: func(ctx context.Context) error {
+			probed <- struct{}{}
+			return nil
+		}}},
+		// Long enough that only Refresh can trigger the second probe.
+		Interval: time.Hour,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx)
+
+	<-probed
+	monitor.Refresh()
+	<-probed
+}
@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultStepTimeout bounds each shutdown step when the manager has no
+// StepTimeout.
+const DefaultStepTimeout = 10 * time.Second
+
+type step struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Manager stops the service's components in the order they were registered
+// once the process is asked to terminate. Each step gets its own deadline, so
+// one that hangs can't keep the later ones from running.
+type Manager struct {
+	StepTimeout time.Duration
+
+	mu    sync.Mutex
+	steps []step
+}
+
+// OnShutdown registers stop to run at shutdown, after every step registered
+// before it.
+func (manager *Manager) OnShutdown(name string, stop func(ctx context.Context) error) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	manager.steps = append(manager.steps, step{name: name, stop: stop})
+}
+
+// WaitForSignal blocks until the process receives SIGINT or SIGTERM or ctx is
+// done, whichever comes first.
+func (manager *Manager) WaitForSignal(ctx context.Context) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+}
+
+// Shutdown runs every registered step in order. A failed step is logged and
+// doesn't stop the rest; all failures are returned together.
+func (manager *Manager) Shutdown() error {
+	manager.mu.Lock()
+	steps := manager.steps
+	manager.steps = nil
+	manager.mu.Unlock()
+
+	var errs []error
+	for _, step := range steps {
+		started := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), manager.stepTimeout())
+		err := step.stop(ctx)
+		cancel()
+		if err != nil {
+			slog.Error("Shutdown step failed", "step", step.name, "elapsed", time.Since(started), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+			continue
+		}
+		slog.Info("Shutdown step done", "step", step.name, "elapsed", time.Since(started))
+	}
+	return errors.Join(errs...)
+}
+
+func (manager *Manager) stepTimeout() time.Duration {
+	if manager.StepTimeout > 0 {
+		return manager.StepTimeout
+	}
+	return DefaultStepTimeout
+}
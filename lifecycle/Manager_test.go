@@ -0,0 +1,59 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownRunsEveryStepInOrder(t *testing.T) {
+	manager := &Manager{StepTimeout: 50 * time.Millisecond}
+	var ran []string
+	step := func(name string, err error) {
+		manager.OnShutdown(name, func(ctx context.Context) error {
+			ran = append(ran, name)
+			return err
+		})
+	}
+	step("grpc", nil)
+	step("outbox", errors.New("flush failed"))
+	// A step that hangs is cut off by its own deadline.
+	manager.OnShutdown("consumer", func(ctx context.Context) error {
+		<-ctx.Done()
+		ran = append(ran, "consumer")
+		return ctx.Err()
+	})
+	step("mongo", nil)
+
+	err := manager.Shutdown()
+	if want := []string{"grpc", "outbox", "consumer", "mongo"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "outbox: flush failed") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want both failures", err)
+	}
+
+	// Steps run once.
+	ran = nil
+	if err := manager.Shutdown(); err != nil || ran != nil {
+		t.Errorf("second Shutdown = %v and ran %v", err, ran)
+	}
+}
+
+func TestWaitForSignalReturnsWhenCtxIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		(&Manager{}).WaitForSignal(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForSignal didn't return")
+	}
+}
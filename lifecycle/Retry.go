@@ -0,0 +1,37 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Backoff is how long Retry waits between attempts: Initial after the first
+// failure, doubling up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Retry calls fn until it succeeds or ctx is done, logging each failure. It
+// returns the last error if it gives up.
+func Retry(ctx context.Context, name string, backoff Backoff, fn func(ctx context.Context) error) error {
+	delay := backoff.Initial
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		slog.WarnContext(ctx, name+" failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: giving up after %d attempts: %w", name, attempt, err)
+		case <-timer.C:
+		}
+		delay = min(delay*2, backoff.Max)
+	}
+}
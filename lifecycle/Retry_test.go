@@ -0,0 +1,35 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryUntilItSucceeds(t *testing.T) {
+	attempts := 0
+	err := Retry(context.Background(), "connect", Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond}, func(ctx context.Context) error {
+		attempts++
+		if attempts < 4 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if err != nil || attempts != 4 {
+		t.Errorf("Retry = %v after %d attempts, want success after 4", err, attempts)
+	}
+}
+
+func TestRetryGivesUpWithTheLastError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	unreachable := errors.New("unreachable")
+
+	err := Retry(ctx, "connect", Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond}, func(ctx context.Context) error {
+		return unreachable
+	})
+	if !errors.Is(err, unreachable) {
+		t.Errorf("Retry = %v, want the last error", err)
+	}
+}
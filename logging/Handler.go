@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"BlogApplication/auth"
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// Keys of the attributes Handler takes from the context.
+const (
+	TraceIdKey        = "trace_id"
+	SpanIdKey         = "span_id"
+	RpcMethodKey      = "rpc.method"
+	PrincipalKey      = "principal.user_id"
+	PrincipalRolesKey = "principal.roles"
+	TrustedCallerKey  = "principal.trusted"
+)
+
+// Handler adds the trace and span, the RPC method and the caller found in
+// the context of each record, so lines logged with the *Context methods can
+// be matched to their trace and request.
+type Handler struct {
+	next slog.Handler
+}
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (handler *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String(TraceIdKey, spanContext.TraceID().String()),
+			slog.String(SpanIdKey, spanContext.SpanID().String()),
+		)
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		record.AddAttrs(slog.String(RpcMethodKey, method))
+	}
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		if principal.UserId != 0 {
+			record.AddAttrs(slog.Int64(PrincipalKey, principal.UserId))
+		}
+		if len(principal.Roles) > 0 {
+			record.AddAttrs(slog.Any(PrincipalRolesKey, principal.Roles))
+		}
+	} else if auth.IsTrustedCaller(ctx) {
+		record.AddAttrs(slog.Bool(TrustedCallerKey, true))
+	}
+	return handler.next.Handle(ctx, record)
+}
+
+func (handler *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: handler.next.WithAttrs(attrs)}
+}
+
+func (handler *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: handler.next.WithGroup(name)}
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"BlogApplication/config"
+	"io"
+	"log/slog"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New builds the service logger writing to output: JSON lines by default, as
+// log collectors expect in production, or text for reading in a terminal.
+// Its level is held by the returned LevelVar, so it can be changed while the
+// service runs.
+func New(cfg config.LoggingConfig, output io.Writer) (*slog.Logger, *slog.LevelVar, error) {
+	level := &slog.LevelVar{}
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: level, AddSource: cfg.AddSource}
+	var handler slog.Handler
+	if cfg.Format == FormatText {
+		handler = slog.NewTextHandler(output, options)
+	} else {
+		handler = slog.NewJSONHandler(output, options)
+	}
+	return slog.New(NewHandler(handler)), level, nil
+}
+
+// OrDefault returns logger, or slog's default logger if it's nil, for
+// components built without one.
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"BlogApplication/auth"
+	"BlogApplication/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// methodStream gives a context the RPC method a server would.
+type methodStream struct{ method string }
+
+func (stream methodStream) Method() string                  { return stream.method }
+func (stream methodStream) SetHeader(metadata.MD) error     { return nil }
+func (stream methodStream) SendHeader(metadata.MD) error    { return nil }
+func (stream methodStream) SetTrailer(md metadata.MD) error { return nil }
+
+func newTestLogger(t *testing.T, level string) (*slog.Logger, *slog.LevelVar, *bytes.Buffer) {
+	t.Helper()
+	var output bytes.Buffer
+	logger, levelVar, err := New(config.LoggingConfig{Level: level, Format: FormatJSON}, &output)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return logger, levelVar, &output
+}
+
+func lines(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %q isn't JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestHandlerAddsRequestFields(t *testing.T) {
+	logger, _, output := newTestLogger(t, "info")
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+	ctx = grpc.NewContextWithServerTransportStream(ctx, methodStream{method: "/BlogMicroservice/Update"})
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{UserId: 7, Roles: []string{auth.RoleAdmin}})
+	logger.InfoContext(ctx, "Blog updated", "blog.id", 1)
+
+	logger.WarnContext(auth.WithTrustedCaller(context.Background()), "Trusted call")
+	logger.Info("No context")
+
+	records := lines(t, output)
+	if len(records) != 3 {
+		t.Fatalf("got %d lines, want 3", len(records))
+	}
+	want := map[string]interface{}{
+		TraceIdKey:        spanContext.TraceID().String(),
+		SpanIdKey:         spanContext.SpanID().String(),
+		RpcMethodKey:      "/BlogMicroservice/Update",
+		PrincipalKey:      float64(7),
+		PrincipalRolesKey: []interface{}{auth.RoleAdmin},
+		"blog.id":         float64(1),
+	}
+	for key, value := range want {
+		if !reflect.DeepEqual(records[0][key], value) {
+			t.Errorf("%s = %v, want %v", key, records[0][key], value)
+		}
+	}
+	if records[1][TrustedCallerKey] != true {
+		t.Errorf("trusted call logged as %v", records[1])
+	}
+	for _, key := range []string{TraceIdKey, RpcMethodKey, PrincipalKey, TrustedCallerKey} {
+		if _, ok := records[2][key]; ok {
+			t.Errorf("line without a context has %s", key)
+		}
+	}
+}
+
+func TestHandlerKeepsAttrsAndGroups(t *testing.T) {
+	logger, _, output := newTestLogger(t, "info")
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserId: 7})
+	logger.With("component", "outbox").WithGroup("batch").InfoContext(ctx, "Sent", "size", 3)
+
+	record := lines(t, output)[0]
+	if record["component"] != "outbox" {
+		t.Errorf("component = %v", record["component"])
+	}
+	if batch, _ := record["batch"].(map[string]interface{}); batch["size"] != float64(3) {
+		t.Errorf("batch = %v", record["batch"])
+	}
+}
+
+func TestNewLevel(t *testing.T) {
+	logger, level, output := newTestLogger(t, "warn")
+	logger.Info("dropped")
+	logger.Warn("kept")
+	level.Set(slog.LevelDebug)
+	logger.Debug("kept after lowering the level")
+
+	records := lines(t, output)
+	if len(records) != 2 || records[0]["msg"] != "kept" {
+		t.Errorf("logged %v", records)
+	}
+
+	if _, _, err := New(config.LoggingConfig{Level: "loud"}, &bytes.Buffer{}); err == nil {
+		t.Error("New accepted level loud")
+	}
+}
+
+func TestNewTextFormat(t *testing.T) {
+	var output bytes.Buffer
+	logger, _, err := New(config.LoggingConfig{Level: "info", Format: FormatText}, &output)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	logger.Info("Started", "port", 8088)
+	if line := output.String(); !strings.Contains(line, "msg=Started") || !strings.Contains(line, "port=8088") {
+		t.Errorf("text line = %q", line)
+	}
+}
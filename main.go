@@ -1,54 +1,92 @@
 package main
 
 import (
+	"BlogApplication/auth"
+	"BlogApplication/config"
+	"BlogApplication/events"
+	"BlogApplication/healthcheck"
+	"BlogApplication/lifecycle"
+	"BlogApplication/logging"
 	"BlogApplication/repository"
 	"BlogApplication/server"
 	"BlogApplication/service"
-	"encoding/json"
+	"BlogApplication/telemetry"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
 
 	"context"
-	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"google.golang.org/grpc/reflection"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
-func initDB() *mongo.Client {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func initDB(ctx context.Context, cfg config.MongoConfig) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://blog-database:27017"))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI).SetMonitor(telemetry.MongoMonitor(otelmongo.NewMonitor())))
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("connecting to MongoDB: %w", err)
 	}
 
-	err = client.Ping(context.Background(), nil)
+	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal(err)
-		return nil
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("pinging MongoDB: %w", err)
 	}
 
-	println("Connected to MongoDB!")
+	slog.InfoContext(ctx, "Connected to MongoDB")
 
-	return client
+	return client, nil
 }
 
-func initTracer() (func(context.Context) error, error) {
+// initTracer installs the tracer and meter providers. Metrics are read by the
+// Prometheus exporter and, if enabled, pushed over OTLP next to the traces.
+func initTracer(cfg config.TelemetryConfig) (func(context.Context) error, error) {
+	telemetry.SetPolicy(telemetry.Policy{
+		MaxTextLength:    cfg.MaxTextLength,
+		DeniedAttributes: cfg.DeniedAttributes,
+	})
 
-	jaegerExporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpoint("jaeger:4318"), otlptracehttp.WithInsecure())
+	exporterOptions := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+	if cfg.OTLPInsecure {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+	jaegerExporter, err := otlptracehttp.New(context.Background(), exporterOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +94,7 @@ func initTracer() (func(context.Context) error, error) {
 	res, err := resource.New(
 		context.Background(),
 		resource.WithAttributes(
-			attribute.String("service.name", "blog-service"),
+			attribute.String("service.name", cfg.ServiceName),
 		),
 	)
 	if err != nil {
@@ -64,88 +102,507 @@ func initTracer() (func(context.Context) error, error) {
 	}
 
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(jaegerExporter),
+		sdktrace.WithBatcher(telemetry.FilterExporter(jaegerExporter)),
 		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
 	)
 
+	prometheusExporter, err := prometheus.New()
+	if err != nil {
+		return nil, err
+	}
+	meterOptions := []sdkmetric.Option{
+		sdkmetric.WithReader(prometheusExporter),
+		sdkmetric.WithResource(res),
+	}
+	if cfg.OTLPMetrics {
+		metricOptions := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			metricOptions = append(metricOptions, otlpmetrichttp.WithInsecure())
+		}
+		metricExporter, err := otlpmetrichttp.New(context.Background(), metricOptions...)
+		if err != nil {
+			return nil, err
+		}
+		meterOptions = append(meterOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(cfg.MetricInterval))))
+	}
+	mp := sdkmetric.NewMeterProvider(meterOptions...)
+
 	otel.SetTracerProvider(tp)
-	return tp.Shutdown, nil
+	otel.SetMeterProvider(mp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), mp.Shutdown(ctx))
+	}, nil
 }
 
-func startServer(blogService *service.BlogService, commentService *service.CommentService, reportService *service.ReportService, natsConn *nats.Conn) {
+// initMetrics registers the gauges that are read from the database when
+// metrics are collected.
+func initMetrics(blogRepository *repository.BlogRepository, outboxRepository *repository.OutboxRepository) error {
+	err := telemetry.ObserveBlogs(func(ctx context.Context) ([]telemetry.BlogCount, error) {
+		counts, err := blogRepository.CountByStatusAndTopic(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blogs := make([]telemetry.BlogCount, 0, len(counts))
+		for _, count := range counts {
+			blogs = append(blogs, telemetry.BlogCount{Status: string(count.Status), Topic: string(count.Topic), Count: count.Count})
+		}
+		return blogs, nil
+	})
+	if err != nil {
+		return err
+	}
+	return telemetry.ObserveOutboxBacklog(outboxRepository.CountPending)
+}
+
+// fatal logs err and exits, for failures the service can't start past.
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
+
+// initAuth builds the RPC authenticator. Tokens can be signed with the HMAC
+// secret, with a key from the JWKS file, or both. Tokenless internal callers
+// are trusted by client certificate when mutual TLS is on, or by network.
+func initAuth(cfg config.AuthConfig, tlsCfg config.TLSConfig) (*server.AuthInterceptor, error) {
+	verifier := &auth.Verifier{
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+		Leeway:   cfg.Leeway,
+	}
+	if cfg.HMACSecret != "" {
+		verifier.HMACSecret = []byte(cfg.HMACSecret)
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := auth.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.RSAKeys = keys
+	}
 
-	grpcServer := grpc.NewServer()
+	var trustedNetworks []netip.Prefix
+	for _, network := range cfg.TrustedNetworks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, err
+		}
+		trustedNetworks = append(trustedNetworks, prefix)
+	}
+
+	return &server.AuthInterceptor{
+		Verifier:                verifier,
+		TrustInternalCallers:    cfg.TrustInternalCallers,
+		TrustClientCertificates: tlsCfg.Enabled && tlsCfg.ClientCAFile != "",
+		TrustedNetworks:         trustedNetworks,
+		PublicMethods: []string{
+			"/grpc.reflection.v1.ServerReflection/",
+			"/grpc.reflection.v1alpha.ServerReflection/",
+			"/grpc.health.v1.Health/",
+			// The public feed can be read without signing in.
+			server.BlogMicroservice_FindPublishedBlogs_FullMethodName,
+			server.BlogMicroservice_FindBlogsByType_FullMethodName,
+			server.BlogMicroservice_FindBlogById_FullMethodName,
+		},
+	}, nil
+}
+
+// initCounters seeds the id counters from the ids already present in each
+// collection, so allocation continues where the old max+1 scheme left off.
+// It runs on every startup; Bootstrap is idempotent and cheap.
+func initCounters(counters *repository.CounterRepository, collections map[string]*mongo.Collection) error {
+	for name, collection := range collections {
+		if err := counters.Bootstrap(context.Background(), name, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	reflection.Register(grpcServer)
+// serverCredentials loads the gRPC server certificate and, for mutual TLS,
+// the CAs client certificates must be signed by.
+func serverCredentials(cfg config.TLSConfig) (credentials.TransportCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// startServer starts serving RPCs in the background. onFailure is called if
+// the server stops for any reason other than a shutdown.
+func startServer(cfg *config.Config, blogService *service.BlogService, commentService *service.CommentService, reportService *service.ReportService, voteService *service.VoteService, deadLetterService *service.DeadLetterService, loggingService *service.LoggingService, logger *slog.Logger, authInterceptor *server.AuthInterceptor, healthServer *health.Server, onFailure func()) *grpc.Server {
+
+	serverOptions := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.ChainUnaryInterceptor(server.ErrorInterceptor, authInterceptor.Unary),
+		grpc.StreamInterceptor(authInterceptor.Stream),
+	}
+	if cfg.Server.TLS.Enabled {
+		creds, err := serverCredentials(cfg.Server.TLS)
+		if err != nil {
+			fatal("Failed to load TLS credentials", err)
+		}
+		serverOptions = append(serverOptions, grpc.Creds(creds))
+	}
+	grpcServer := grpc.NewServer(serverOptions...)
+
+	if cfg.Features.Reflection {
+		reflection.Register(grpcServer)
+	}
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	blogMicroservice := &server.BlogMicroservice{
 		BlogService:    blogService,
 		CommentService: commentService,
 		ReportService:  reportService,
-		NatsConn:       natsConn,
+		VoteService:    voteService,
+
+		DeadLetterService: deadLetterService,
+		LoggingService:    loggingService,
+
+		Logger: logger,
 	}
 
 	server.RegisterBlogMicroserviceServer(grpcServer, blogMicroservice)
 
-	listener, err := net.Listen("tcp", ":8088")
+	listener, err := net.Listen("tcp", cfg.Server.ListenAddress)
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		fatal("Failed to listen", err)
 	}
 
-	log.Println("gRPC server listening on port :8088")
-	if err := grpcServer.Serve(listener); err != nil {
-		log.Fatalf("Failed to serve gRPC server: %v", err)
-	}
+	logger.Info("gRPC server listening", "address", cfg.Server.ListenAddress)
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Error("Failed to serve gRPC server", "error", err)
+			onFailure()
+		}
+	}()
 
+	return grpcServer
 }
-func Conn() *nats.Conn {
-	conn, err := nats.Connect("nats://nats:4222")
-	if err != nil {
-		log.Fatal(err)
+
+// stopServer waits for in-flight RPCs to finish, and cuts them off if they
+// haven't by the time ctx is done.
+func stopServer(ctx context.Context, grpcServer *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		grpcServer.Stop()
+		return ctx.Err()
 	}
-	return conn
 }
-func handleRollback(nc *nats.Conn, commentService *service.CommentService) {
-	nc.Subscribe("comment.creation.rollback", func(m *nats.Msg) {
-		var event struct {
-			CommentID int `json:"comment_id"`
-		}
-		json.Unmarshal(m.Data, &event)
 
-		// Delete the comment
-		err := commentService.Delete(context.Background(), int64(event.CommentID))
-		if err != nil {
-			log.Printf("Failed to rollback comment creation: %v", err)
-		} else {
-			log.Printf("Successfully rolled back comment creation with ID: %d", event.CommentID)
-		}
-	})
+// Conn connects to NATS and keeps reconnecting for as long as the process
+// runs. onStatusChange is called whenever the connection drops or comes back.
+func Conn(cfg config.NatsConfig, onStatusChange func()) (*nats.Conn, error) {
+	natsOptions := []nats.Option{
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			slog.Warn("Disconnected from NATS", "error", err)
+			onStatusChange()
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			slog.Info("Reconnected to NATS", "url", conn.ConnectedUrlRedacted())
+			onStatusChange()
+		}),
+	}
+	if cfg.CredentialsFile != "" {
+		natsOptions = append(natsOptions, nats.UserCredentials(cfg.CredentialsFile))
+	}
+	if cfg.User != "" {
+		natsOptions = append(natsOptions, nats.UserInfo(cfg.User, cfg.Password))
+	}
+	if cfg.Token != "" {
+		natsOptions = append(natsOptions, nats.Token(cfg.Token))
+	}
+	if cfg.CAFile != "" {
+		natsOptions = append(natsOptions, nats.RootCAs(cfg.CAFile))
+	}
+	return nats.Connect(cfg.URL, natsOptions...)
+}
+
+// drainConn flushes pending publishes, lets subscriptions finish the messages
+// they already have and closes the connection.
+func drainConn(ctx context.Context, conn *nats.Conn) error {
+	closed := make(chan struct{})
+	conn.SetClosedHandler(func(*nats.Conn) { close(closed) })
+	if err := conn.Drain(); err != nil {
+		return err
+	}
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		conn.Close()
+		return ctx.Err()
+	}
 }
 func main() {
-	client := initDB()
-	if client == nil {
-		print("FAILED TO CONNECT TO DB")
-		return
+	configPath := flag.String("config", "", "path to a YAML or TOML config file, defaults to $"+config.FileEnv)
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("FAILED TO LOAD CONFIG: %v", err)
+	}
+
+	logger, logLevel, err := logging.New(cfg.Logging, os.Stdout)
+	if err != nil {
+		log.Fatalf("FAILED TO INITIALIZE LOGGER: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	lifecycleManager := &lifecycle.Manager{StepTimeout: cfg.Lifecycle.ShutdownTimeout}
+	backoff := lifecycle.Backoff{Initial: cfg.Lifecycle.RetryInitialBackoff, Max: cfg.Lifecycle.RetryMaxBackoff}
+	startupCtx, cancelStartup := context.WithTimeout(context.Background(), cfg.Lifecycle.StartupTimeout)
+	defer cancelStartup()
+
+	healthMonitor := &healthcheck.Monitor{
+		Health:   health.NewServer(),
+		Services: []string{server.BlogMicroservice_ServiceDesc.ServiceName},
+		Interval: cfg.Health.CheckInterval,
+		Timeout:  cfg.Health.CheckTimeout,
+	}
+
+	var client *mongo.Client
+	err = lifecycle.Retry(startupCtx, "MongoDB connection", backoff, func(ctx context.Context) error {
+		client, err = initDB(ctx, cfg.Mongo)
+		return err
+	})
+	if err != nil {
+		fatal("Failed to connect to MongoDB", err)
+	}
+
+	shutdown, err := initTracer(cfg.Telemetry)
+	if err != nil {
+		fatal("Failed to initialize tracer", err)
+	}
+
+	authInterceptor, err := initAuth(cfg.Auth, cfg.Server.TLS)
+	if err != nil {
+		fatal("Failed to initialize auth", err)
+	}
+
+	var conn *nats.Conn
+	err = lifecycle.Retry(startupCtx, "NATS connection", backoff, func(ctx context.Context) error {
+		conn, err = Conn(cfg.Nats, healthMonitor.Refresh)
+		return err
+	})
+	if err != nil {
+		fatal("Failed to connect to NATS", err)
+	}
+
+	healthMonitor.Checks = []healthcheck.Check{healthcheck.MongoCheck(client), healthcheck.NatsCheck(conn)}
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	go healthMonitor.Run(monitorCtx)
+
+	counterRepository := repository.NewCounterRepository(client, cfg.Mongo, logger)
+	policy := &service.Policy{AuditRepository: repository.NewAuditRepository(client, cfg.Mongo, logger), Logger: logger}
+
+	outboxRepository := repository.NewOutboxRepository(client, cfg.Mongo, counterRepository, logger)
+	outboxRelay := &service.OutboxRelay{OutboxRepository: outboxRepository, Publisher: &events.NatsPublisher{Conn: conn}, Interval: cfg.Features.OutboxInterval, BatchSize: cfg.Features.OutboxBatchSize, Logger: logger}
+	transactions, err := repository.NewTransactionRunner(context.Background(), client, cfg.Mongo.AllowStandalone, logger)
+	if err != nil {
+		fatal("Failed to check MongoDB transaction support", err)
+	}
+	outbox := &service.Outbox{
+		Transactions: transactions,
+		Repository:   outboxRepository,
+		Relay:        outboxRelay,
+	}
+
+	blogRepository := repository.NewBlogRepository(client, cfg.Mongo, counterRepository, logger)
+	revisionRepository := repository.NewBlogRevisionRepository(client, cfg.Mongo, counterRepository, logger)
+	voteRepository := repository.NewVoteRepository(client, cfg.Mongo, counterRepository, logger)
+	blogService := &service.BlogService{BlogRepository: blogRepository, RevisionRepository: revisionRepository, VoteRepository: voteRepository, Policy: policy, Outbox: outbox, Logger: logger}
+
+	commentRepository := repository.NewCommentRepository(client, cfg.Mongo, counterRepository, logger)
+	commentService := &service.CommentService{CommentRepo: commentRepository, BlogService: blogService, MaxDepth: cfg.Features.MaxCommentDepth, SagaEnabled: cfg.Features.CommentSaga, Policy: policy, Outbox: outbox, Logger: logger}
+
+	reportRepository := repository.NewReportRepository(client, cfg.Mongo, counterRepository, logger)
+	reportService := &service.ReportService{ReportRepository: reportRepository, Outbox: outbox, Logger: logger}
+
+	voteService := &service.VoteService{VoteRepo: voteRepository, BlogService: blogService, Outbox: outbox, Logger: logger}
+
+	err = initCounters(counterRepository, map[string]*mongo.Collection{
+		repository.BlogCounter:     blogRepository.Collection,
+		repository.CommentCounter:  commentRepository.Collection,
+		repository.ReportCounter:   reportRepository.Collection,
+		repository.RevisionCounter: revisionRepository.Collection,
+		repository.VoteCounter:     voteRepository.Collection,
+		repository.OutboxCounter:   outboxRepository.Collection,
+	})
+	if err != nil {
+		fatal("Failed to initialize id counters", err)
 	}
-	shutdown, err := initTracer()
+
+	err = commentService.BackfillCommentCounts(context.Background())
+	if err != nil {
+		fatal("Failed to backfill comment counts", err)
+	}
+
+	err = voteRepository.EnsureIndexes(context.Background())
+	if err != nil {
+		fatal("Failed to create vote indexes", err)
+	}
+	err = voteService.MigrateEmbeddedVotes(context.Background())
+	if err != nil {
+		fatal("Failed to migrate embedded votes", err)
+	}
+
+	err = outboxRepository.EnsureIndexes(context.Background())
+	if err != nil {
+		fatal("Failed to create outbox indexes", err)
+	}
+
+	err = initMetrics(blogRepository, outboxRepository)
+	if err != nil {
+		fatal("Failed to initialize metrics", err)
+	}
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		outboxRelay.Run(workersCtx)
+	}()
+
+	if cfg.Features.PublishScheduler {
+		publishScheduler := &service.PublishScheduler{BlogService: blogService, Interval: cfg.Features.PublishInterval, Logger: logger}
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			publishScheduler.Run(workersCtx)
+		}()
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		fatal("Failed to connect to JetStream", err)
+	}
+	deadLetters := &events.DeadLetterQueue{JetStream: js, Stream: "DEAD_LETTERS", SubjectPrefix: "deadletter.blog-service"}
+	err = lifecycle.Retry(startupCtx, "dead-letter stream", backoff, deadLetters.EnsureStream)
 	if err != nil {
-		log.Fatalf("FAILED TO INITIALIZE TRACER: %v", err)
+		fatal("Failed to create dead-letter stream", err)
 	}
-	defer shutdown(context.Background())
+	deadLetterService := &service.DeadLetterService{Queue: deadLetters, Policy: policy, Logger: logger}
+	loggingService := &service.LoggingService{Level: logLevel, Policy: policy, Logger: logger}
 
-	conn := Conn()
-	defer conn.Close()
+	var sagaConsumer jetstream.ConsumeContext
+	if cfg.Features.CommentSaga {
+		commentSaga := &service.CommentSaga{CommentService: commentService, JetStream: js, DeadLetters: deadLetters, Logger: logger}
+		err = lifecycle.Retry(startupCtx, "comment saga consumer", backoff, func(ctx context.Context) error {
+			sagaConsumer, err = commentSaga.Start(ctx)
+			return err
+		})
+		if err != nil {
+			fatal("Failed to start comment saga consumer", err)
+		}
+	}
 
-	blogRepository := repository.NewBlogRepository(client)
-	blogService := &service.BlogService{BlogRepository: blogRepository}
+	runCtx, stopRunning := context.WithCancel(context.Background())
+	grpcServer := startServer(cfg, blogService, commentService, reportService, voteService, deadLetterService, loggingService, logger, authInterceptor, healthMonitor.Health, stopRunning)
 
-	commentRepository := repository.NewCommentRepository(client)
-	commentService := &service.CommentService{CommentRepo: commentRepository}
+	var healthHTTPServer *http.Server
+	if cfg.Health.HTTPAddress != "" {
+		healthHTTPServer = &http.Server{Addr: cfg.Health.HTTPAddress, Handler: healthcheck.Handler(healthMonitor), ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := healthHTTPServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Failed to serve health endpoints", "error", err)
+				stopRunning()
+			}
+		}()
+		logger.Info("Health endpoints listening", "address", cfg.Health.HTTPAddress)
+	}
 
-	reportRepository := repository.NewReportRepository(client)
-	reportService := &service.ReportService{ReportRepository: reportRepository}
+	var metricsHTTPServer *http.Server
+	if cfg.Telemetry.MetricsAddress != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", promhttp.Handler())
+		metricsHTTPServer = &http.Server{Addr: cfg.Telemetry.MetricsAddress, Handler: metricsMux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := metricsHTTPServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Failed to serve metrics", "error", err)
+				stopRunning()
+			}
+		}()
+		logger.Info("Metrics listening", "address", cfg.Telemetry.MetricsAddress)
+	}
 
-	handleRollback(conn, commentService)
-	startServer(blogService, commentService, reportService, conn)
+	// Components stop in dependency order: nothing new comes in over gRPC or
+	// NATS, then whatever the service still owes the broker is flushed, and
+	// Mongo goes last because every step before it may still write to it.
+	lifecycleManager.OnShutdown("health status", func(ctx context.Context) error {
+		stopMonitor()
+		healthMonitor.Stop()
+		return nil
+	})
+	lifecycleManager.OnShutdown("gRPC server", func(ctx context.Context) error {
+		return stopServer(ctx, grpcServer)
+	})
+	if healthHTTPServer != nil {
+		lifecycleManager.OnShutdown("health endpoints", healthHTTPServer.Shutdown)
+	}
+	if metricsHTTPServer != nil {
+		lifecycleManager.OnShutdown("metrics endpoint", metricsHTTPServer.Shutdown)
+	}
+	lifecycleManager.OnShutdown("background workers", func(ctx context.Context) error {
+		stopWorkers()
+		done := make(chan struct{})
+		go func() {
+			workers.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	lifecycleManager.OnShutdown("NATS subscriptions", func(ctx context.Context) error {
+		if sagaConsumer != nil {
+			sagaConsumer.Drain()
+		}
+		return nil
+	})
+	lifecycleManager.OnShutdown("outbox", func(ctx context.Context) error {
+		_, err := outboxRelay.RelayPending(ctx)
+		return err
+	})
+	lifecycleManager.OnShutdown("NATS connection", func(ctx context.Context) error {
+		return drainConn(ctx, conn)
+	})
+	lifecycleManager.OnShutdown("telemetry", shutdown)
+	lifecycleManager.OnShutdown("MongoDB", client.Disconnect)
 
-	select {}
+	lifecycleManager.WaitForSignal(runCtx)
+	logger.Info("Shutting down")
+	if err := lifecycleManager.Shutdown(); err != nil {
+		os.Exit(1)
+	}
 }
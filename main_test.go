@@ -0,0 +1,79 @@
+package main
+
+import (
+	"BlogApplication/config"
+	"BlogApplication/server"
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// feedServer answers the public feed RPCs with empty results and leaves the
+// rest unimplemented.
+type feedServer struct {
+	server.UnimplementedBlogMicroserviceServer
+}
+
+func (feedServer) FindBlogById(context.Context, *server.BlogIdRequest) (*server.BlogResponse, error) {
+	return &server.BlogResponse{}, nil
+}
+
+func (feedServer) FindBlogsByType(context.Context, *server.TypeRequest) (*server.BlogListResponse, error) {
+	return &server.BlogListResponse{}, nil
+}
+
+func (feedServer) FindPublishedBlogs(context.Context, *server.PageRequest) (*server.BlogListResponse, error) {
+	return &server.BlogListResponse{}, nil
+}
+
+// TestPublicFeedNeedsNoToken serves the interceptor initAuth builds the way
+// main does, so the allow-list is checked against the method names the
+// generated handlers really report.
+func TestPublicFeedNeedsNoToken(t *testing.T) {
+	authInterceptor, err := initAuth(config.AuthConfig{HMACSecret: "secret"}, config.TLSConfig{})
+	if err != nil {
+		t.Fatalf("initAuth: %v", err)
+	}
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.ErrorInterceptor, authInterceptor.Unary),
+		grpc.StreamInterceptor(authInterceptor.Stream),
+	)
+	server.RegisterBlogMicroserviceServer(grpcServer, feedServer{})
+	listener := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	method := func(name string) string { return "/" + server.BlogMicroservice_ServiceDesc.ServiceName + "/" + name }
+	ctx := context.Background()
+	calls := []struct {
+		method string
+		req    interface{}
+		reply  interface{}
+		want   codes.Code
+	}{
+		{method("FindBlogById"), &server.BlogIdRequest{Id: 1}, &server.BlogResponse{}, codes.OK},
+		{method("FindBlogsByType"), &server.TypeRequest{}, &server.BlogListResponse{}, codes.OK},
+		{method("FindPublishedBlogs"), &server.PageRequest{}, &server.BlogListResponse{}, codes.OK},
+		{method("UpdateBlog"), &server.BlogUpdateRequest{}, &server.BlogResponse{}, codes.Unauthenticated},
+	}
+	for _, call := range calls {
+		err := conn.Invoke(ctx, call.method, call.req, call.reply)
+		if code := status.Code(err); code != call.want {
+			t.Errorf("%s without a token: %v, want %s", call.method, err, call.want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// AuditEntry records an action a caller was denied.
+type AuditEntry struct {
+	ActorId    int64     `json:"actorId"`
+	Roles      []string  `json:"roles"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	ResourceId int64     `json:"resourceId"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -1,7 +1,6 @@
 package model
 
 import (
-	"errors"
 	"fmt"
 	"time"
 )
@@ -38,6 +37,17 @@ const (
 	PrivateBlog BlogVisibilityPolicy = "private"
 )
 
+// ListedStatuses are the statuses under which a public blog shows up in
+// listings. Drafts and closed blogs are only visible to their author.
+var ListedStatuses = []BlogStatus{Published, Active, Famous}
+
+// BlogViewer describes who is listing blogs. UserId is zero for anonymous
+// viewers; IncludeHidden is reserved for administrators.
+type BlogViewer struct {
+	UserId        int64
+	IncludeHidden bool
+}
+
 type Blog struct {
 	Id          int        `json:"id" gorm:"primaryKey"`
 	Title       string     `json:"title"`
@@ -47,17 +57,19 @@ type Blog struct {
 	AuthorId    int64      `json:"authorId"`
 	//ClubId        *int64               `json:"clubId,omitempty"` // Optional club ID
 	Comments      []Comment            `json:"comments"`
-	Votes         []Vote               `json:"votes" gorm:"foreignKey:BlogId"`
+	Votes         []Vote               `json:"votes" bson:"-"`
 	Visibility    BlogVisibilityPolicy `json:"visibility"`
 	VoteCount     int64                `json:"voteCount"`
 	UpvoteCount   int64                `json:"upvoteCount"`
 	DownvoteCount int64                `json:"downvoteCount"`
 	BlogTopic     BlogTopicType        `json:"blogTopic"`
+	PublishAt     *time.Time           `json:"publishAt,omitempty"`
+	CommentCount  int64                `json:"commentCount"`
 }
 
 func NewBlog(title string, description string, date time.Time, status BlogStatus, authorId int64, visibility BlogVisibilityPolicy) (*Blog, error) {
 	if title == "" {
-		return nil, fmt.Errorf("title cannot be empty or null")
+		return nil, InvalidField("title", "title cannot be empty or null")
 	}
 
 	blog := &Blog{
@@ -69,83 +81,107 @@ func NewBlog(title string, description string, date time.Time, status BlogStatus
 		Visibility:  visibility,
 		//ClubId:      nil,
 	}
-	blog.calculateVoteCounts()
 	return blog, nil
 }
 
-func (b *Blog) calculateVoteCounts() {
-	b.VoteCount = 0
-	b.UpvoteCount = 0
-	b.DownvoteCount = 0
-	for _, vote := range b.Votes {
-		if vote.VoteType == "UPVOTE" {
-			b.VoteCount++
-			b.UpvoteCount++
-		} else {
-			b.VoteCount--
-			b.DownvoteCount++
-		}
-	}
-}
-
 func (b *Blog) Validate() error {
+	var violations []FieldViolation
+
 	if b.Title == "" {
-		return errors.New("title can't be empty")
+		violations = append(violations, FieldViolation{Field: "title", Description: "title can't be empty"})
 	}
 
 	if b.Description == "" {
-		return errors.New("description can't be empty")
+		violations = append(violations, FieldViolation{Field: "description", Description: "description can't be empty"})
 	}
 
 	if b.Status == "" {
-		return errors.New("status can't be empty")
+		violations = append(violations, FieldViolation{Field: "status", Description: "status can't be empty"})
 	}
 
 	if b.Visibility == "" {
-		return errors.New("visibility can't be empty")
+		violations = append(violations, FieldViolation{Field: "visibility", Description: "visibility can't be empty"})
+	} else if b.Visibility != PublicBlog && b.Visibility != PrivateBlog {
+		violations = append(violations, FieldViolation{Field: "visibility", Description: fmt.Sprintf("invalid visibility: %s, allowed values are 'public' or 'private'", b.Visibility)})
 	}
 
 	if _, err := ParseBlogTopicType(string(b.BlogTopic)); err != nil {
-		return fmt.Errorf("invalid blog topic type: %w", err)
+		violations = append(violations, FieldViolation{Field: "blog_topic", Description: err.Error()})
+	}
+
+	return ValidationError(violations)
+}
+
+// IsListed reports whether the blog is visible to everyone.
+func (b *Blog) IsListed() bool {
+	if b.Visibility != PublicBlog {
+		return false
+	}
+	for _, status := range ListedStatuses {
+		if b.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsVisibleTo reports whether viewer may see the blog.
+func (b *Blog) IsVisibleTo(viewer BlogViewer) bool {
+	return viewer.IncludeHidden || b.IsListed() || (viewer.UserId > 0 && viewer.UserId == b.AuthorId)
+}
+
+// Publish makes a draft live. Its date becomes the publication time so it
+// shows up at the top of the feed, and its status reflects any votes and
+// comments it already has.
+func (b *Blog) Publish(now time.Time) error {
+	if b.Status != Draft {
+		return FailedPrecondition("only drafts can be published, blog is %s", b.Status)
 	}
+	b.Status = Published
+	b.Date = now
+	b.PublishAt = nil
+	b.UpdateBlogStatus()
+	return nil
+}
 
+// Unpublish turns a live blog back into a draft.
+func (b *Blog) Unpublish() error {
+	if b.Status == Draft || b.Status == Closed {
+		return FailedPrecondition("%s blogs can't be unpublished", b.Status)
+	}
+	b.Status = Draft
+	b.PublishAt = nil
+	return nil
+}
+
+// SchedulePublish marks a draft to be published automatically at publishAt.
+func (b *Blog) SchedulePublish(publishAt time.Time, now time.Time) error {
+	if b.Status != Draft {
+		return FailedPrecondition("only drafts can be scheduled, blog is %s", b.Status)
+	}
+	if !publishAt.After(now) {
+		return InvalidField("publish_at", "publish time must be in the future")
+	}
+	b.PublishAt = &publishAt
 	return nil
 }
 
 func (b *Blog) UpdateBlogStatus() {
+	if b.Status == Draft {
+		return
+	}
 	switch {
 	case b.VoteCount < -2:
 		b.Status = "closed"
-	case b.VoteCount >= 3 && len(b.Comments) >= 3:
+	case b.VoteCount >= 3 && b.CommentCount >= 3:
 		b.Status = "famous"
-	case b.VoteCount >= 2 && len(b.Comments) >= 2:
+	case b.VoteCount >= 2 && b.CommentCount >= 2:
 		b.Status = "active"
 	default:
 		b.Status = "published"
 	}
 }
 
-func (b *Blog) SetVote(userID int64, voteType VoteType) error {
-	for i, vote := range b.Votes {
-		if vote.UserId == userID {
-			if vote.VoteType == voteType {
-				return nil
-			}
-			t := b.Votes[i]
-			b.Votes[i] = Vote{Id: t.Id, UserId: userID, BlogId: int64(b.Id), VoteType: voteType} // Replace vote directly
-			b.calculateVoteCounts()
-			b.UpdateBlogStatus()
-			return nil
-		}
-	}
-
-	newVote := Vote{UserId: userID, BlogId: int64(b.Id), VoteType: voteType}
-	b.Votes = append(b.Votes, newVote)
-	b.calculateVoteCounts()
-	b.UpdateBlogStatus()
-
-	return nil
-}
 func ParseBlogTopicType(topicTypeStr string) (BlogTopicType, error) {
 	switch topicTypeStr {
 	case string(BlogTopicTypeBiking):
@@ -169,6 +205,6 @@ func ParseBlogTopicType(topicTypeStr string) (BlogTopicType, error) {
 	case string(BlogTopicTypeArt):
 		return BlogTopicTypeArt, nil
 	default:
-		return "", fmt.Errorf("invalid blog topic type: %s", topicTypeStr)
+		return "", InvalidField("blog_topic", "invalid blog topic type: %s", topicTypeStr)
 	}
 }
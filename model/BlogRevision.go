@@ -0,0 +1,55 @@
+package model
+
+import (
+	"BlogApplication/useCases"
+	"time"
+)
+
+// BlogRevision is an immutable snapshot of a blog's editable content, taken
+// every time the content changes.
+type BlogRevision struct {
+	Id          int                  `json:"id"`
+	BlogId      int64                `json:"blogId"`
+	EditorId    int64                `json:"editorId"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	BlogTopic   BlogTopicType        `json:"blogTopic"`
+	Visibility  BlogVisibilityPolicy `json:"visibility"`
+}
+
+func NewBlogRevision(blog *Blog, editorId int64, createdAt time.Time) *BlogRevision {
+	return &BlogRevision{
+		BlogId:      int64(blog.Id),
+		EditorId:    editorId,
+		CreatedAt:   createdAt,
+		Title:       blog.Title,
+		Description: blog.Description,
+		BlogTopic:   blog.BlogTopic,
+		Visibility:  blog.Visibility,
+	}
+}
+
+// ApplyTo copies the revision's title and description back onto blog. Topic
+// and visibility are left alone: visibility is how a blog is blocked, and
+// restoring an old revision mustn't unblock it.
+func (r *BlogRevision) ApplyTo(blog *Blog) {
+	blog.Title = r.Title
+	blog.Description = r.Description
+}
+
+// FieldDiff is the line-level diff of one blog field between two revisions.
+type FieldDiff struct {
+	Field string              `json:"field"`
+	Lines []useCases.DiffLine `json:"lines"`
+}
+
+// Diff compares the content of r with to, field by field.
+func (r *BlogRevision) Diff(to *BlogRevision) []FieldDiff {
+	return []FieldDiff{
+		{Field: "title", Lines: useCases.DiffLines(r.Title, to.Title)},
+		{Field: "description", Lines: useCases.DiffLines(r.Description, to.Description)},
+		{Field: "blog_topic", Lines: useCases.DiffLines(string(r.BlogTopic), string(to.BlogTopic))},
+		{Field: "visibility", Lines: useCases.DiffLines(string(r.Visibility), string(to.Visibility))},
+	}
+}
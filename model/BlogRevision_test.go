@@ -0,0 +1,60 @@
+package model
+
+import (
+	"BlogApplication/useCases"
+	"testing"
+	"time"
+)
+
+func TestBlogRevisionApplyToRestoresOnlyContent(t *testing.T) {
+	revision := NewBlogRevision(&Blog{
+		Id:          1,
+		Title:       "Old title",
+		Description: "Old description",
+		BlogTopic:   BlogTopicTypeFood,
+		Visibility:  PublicBlog,
+	}, 2, time.Now())
+
+	// Since the revision, the blog was edited and then blocked.
+	blog := Blog{
+		Id:          1,
+		Title:       "New title",
+		Description: "New description",
+		BlogTopic:   BlogTopicTypeArt,
+		Visibility:  PrivateBlog,
+	}
+	revision.ApplyTo(&blog)
+
+	if blog.Title != "Old title" || blog.Description != "Old description" {
+		t.Errorf("content wasn't restored: %+v", blog)
+	}
+	if blog.Visibility != PrivateBlog {
+		t.Error("restoring a revision unblocked the blog")
+	}
+	if blog.BlogTopic != BlogTopicTypeArt {
+		t.Errorf("topic = %s, want it left alone", blog.BlogTopic)
+	}
+}
+
+func TestBlogRevisionDiff(t *testing.T) {
+	from := &BlogRevision{Title: "Title", Description: "one\ntwo", BlogTopic: BlogTopicTypeFood, Visibility: PublicBlog}
+	to := &BlogRevision{Title: "Title", Description: "one\nthree", BlogTopic: BlogTopicTypeFood, Visibility: PrivateBlog}
+
+	diffs := from.Diff(to)
+	fields := map[string][]useCases.DiffLine{}
+	for _, diff := range diffs {
+		fields[diff.Field] = diff.Lines
+	}
+	if len(diffs) != 4 {
+		t.Fatalf("got %d field diffs, want 4", len(diffs))
+	}
+	if lines := fields["title"]; len(lines) != 1 || lines[0].Op != useCases.DiffEqual {
+		t.Errorf("title diff = %+v, want unchanged", lines)
+	}
+	if lines := fields["description"]; len(lines) != 3 || lines[1].Op != useCases.DiffDelete || lines[2].Op != useCases.DiffInsert {
+		t.Errorf("description diff = %+v", lines)
+	}
+	if lines := fields["visibility"]; len(lines) != 2 || lines[0].Text != "public" || lines[1].Text != "private" {
+		t.Errorf("visibility diff = %+v", lines)
+	}
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlogIsListed(t *testing.T) {
+	tests := []struct {
+		status     BlogStatus
+		visibility BlogVisibilityPolicy
+		want       bool
+	}{
+		{Published, PublicBlog, true},
+		{Active, PublicBlog, true},
+		{Famous, PublicBlog, true},
+		{Draft, PublicBlog, false},
+		{Closed, PublicBlog, false},
+		{Published, PrivateBlog, false},
+		{Famous, PrivateBlog, false},
+	}
+	for _, test := range tests {
+		blog := Blog{Status: test.status, Visibility: test.visibility}
+		if got := blog.IsListed(); got != test.want {
+			t.Errorf("%s %s blog: IsListed() = %v, want %v", test.visibility, test.status, got, test.want)
+		}
+	}
+}
+
+func TestBlogIsVisibleTo(t *testing.T) {
+	listed := Blog{AuthorId: 1, Status: Published, Visibility: PublicBlog}
+	hidden := Blog{AuthorId: 1, Status: Draft, Visibility: PrivateBlog}
+
+	tests := []struct {
+		name   string
+		blog   Blog
+		viewer BlogViewer
+		want   bool
+	}{
+		{"listed blog, anonymous", listed, BlogViewer{}, true},
+		{"listed blog, other user", listed, BlogViewer{UserId: 2}, true},
+		{"hidden blog, anonymous", hidden, BlogViewer{}, false},
+		{"hidden blog, other user", hidden, BlogViewer{UserId: 2}, false},
+		{"hidden blog, author", hidden, BlogViewer{UserId: 1}, true},
+		{"hidden blog, administrator", hidden, BlogViewer{UserId: 2, IncludeHidden: true}, true},
+	}
+	for _, test := range tests {
+		if got := test.blog.IsVisibleTo(test.viewer); got != test.want {
+			t.Errorf("%s: IsVisibleTo() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestBlogPublish(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	scheduled := now.Add(time.Hour)
+	blog := Blog{Status: Draft, Date: now.Add(-24 * time.Hour), PublishAt: &scheduled}
+
+	if err := blog.Publish(now); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if blog.Status != Published || !blog.Date.Equal(now) || blog.PublishAt != nil {
+		t.Errorf("published blog = %+v", blog)
+	}
+
+	if err := blog.Publish(now); KindOf(err) != KindFailedPrecondition {
+		t.Errorf("publishing twice = %v, want a failed precondition", err)
+	}
+}
+
+func TestBlogPublishKeepsEarnedStatus(t *testing.T) {
+	blog := Blog{Status: Draft, VoteCount: 3, CommentCount: 3}
+	if err := blog.Publish(time.Now()); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if blog.Status != Famous {
+		t.Errorf("status = %s, want %s", blog.Status, Famous)
+	}
+}
+
+func TestBlogUnpublish(t *testing.T) {
+	for _, status := range []BlogStatus{Published, Active, Famous} {
+		blog := Blog{Status: status}
+		if err := blog.Unpublish(); err != nil || blog.Status != Draft {
+			t.Errorf("unpublishing a %s blog = %v, status %s", status, err, blog.Status)
+		}
+	}
+	for _, status := range []BlogStatus{Draft, Closed} {
+		blog := Blog{Status: status}
+		if err := blog.Unpublish(); KindOf(err) != KindFailedPrecondition || blog.Status != status {
+			t.Errorf("unpublishing a %s blog = %v, status %s", status, err, blog.Status)
+		}
+	}
+}
+
+func TestBlogSchedulePublish(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	blog := Blog{Status: Draft}
+	if err := blog.SchedulePublish(now.Add(time.Hour), now); err != nil {
+		t.Fatalf("SchedulePublish: %v", err)
+	}
+	if blog.PublishAt == nil || !blog.PublishAt.Equal(now.Add(time.Hour)) || blog.Status != Draft {
+		t.Errorf("scheduled blog = %+v", blog)
+	}
+
+	if err := (&Blog{Status: Draft}).SchedulePublish(now, now); KindOf(err) != KindInvalidArgument {
+		t.Errorf("scheduling in the past = %v, want an invalid argument", err)
+	}
+	if err := (&Blog{Status: Published}).SchedulePublish(now.Add(time.Hour), now); KindOf(err) != KindFailedPrecondition {
+		t.Errorf("scheduling a published blog = %v, want a failed precondition", err)
+	}
+}
+
+func TestBlogUpdateBlogStatus(t *testing.T) {
+	tests := []struct {
+		votes, comments int64
+		want            BlogStatus
+	}{
+		{0, 0, Published},
+		{2, 1, Published},
+		{2, 2, Active},
+		{3, 2, Active},
+		{3, 3, Famous},
+		{-3, 5, Closed},
+	}
+	for _, test := range tests {
+		blog := Blog{Status: Published, VoteCount: test.votes, CommentCount: test.comments}
+		blog.UpdateBlogStatus()
+		if blog.Status != test.want {
+			t.Errorf("%d votes and %d comments: status %s, want %s", test.votes, test.comments, blog.Status, test.want)
+		}
+	}
+
+	draft := Blog{Status: Draft, VoteCount: 5, CommentCount: 5}
+	draft.UpdateBlogStatus()
+	if draft.Status != Draft {
+		t.Errorf("draft became %s", draft.Status)
+	}
+}
@@ -1,17 +1,36 @@
 package model
 
 import (
-	"errors"
+	"sort"
 	"time"
 )
 
+// DeletedCommentText replaces the text of a deleted comment that still has
+// replies, so the thread under it stays intact.
+const DeletedCommentText = "[deleted]"
+
+// CommentSagaState tracks a comment through the creation saga. A new comment
+// stays pending, and hidden, until the saga confirms it. Comments stored
+// before the saga existed have no state and count as confirmed.
+type CommentSagaState string
+
+const (
+	CommentPending     CommentSagaState = "pending"
+	CommentConfirmed   CommentSagaState = "confirmed"
+	CommentCompensated CommentSagaState = "compensated"
+)
+
 type Comment struct {
-	Id        int       `json:"id"`
-	AuthorId  int64     `json:"authorId"`
-	BlogId    int64     `json:"blogId"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt,omitempty"`
-	Text      string    `json:"text"`
+	Id        int              `json:"id"`
+	AuthorId  int64            `json:"authorId"`
+	BlogId    int64            `json:"blogId"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt,omitempty"`
+	Text      string           `json:"text"`
+	ParentId  *int             `json:"parentId,omitempty"`
+	Depth     int              `json:"depth"`
+	Deleted   bool             `json:"deleted,omitempty"`
+	SagaState CommentSagaState `json:"sagaState,omitempty"`
 }
 
 func NewComment(authorId, blogId int64, createdAt time.Time, updatedAt time.Time, text string) (*Comment, error) {
@@ -25,13 +44,98 @@ func NewComment(authorId, blogId int64, createdAt time.Time, updatedAt time.Time
 }
 
 func (c *Comment) Validate() error {
+	var violations []FieldViolation
+
 	if c.BlogId <= 0 {
-		return errors.New("blog ID must be a positive integer")
+		violations = append(violations, FieldViolation{Field: "blog_id", Description: "blog ID must be a positive integer"})
 	}
 
 	if c.Text == "" {
-		return errors.New("comment text cannot be empty")
+		violations = append(violations, FieldViolation{Field: "text", Description: "comment text cannot be empty"})
 	}
 
+	return ValidationError(violations)
+}
+
+// IsConfirmed reports whether the creation saga let the comment through.
+func (c *Comment) IsConfirmed() bool {
+	return c.SagaState == "" || c.SagaState == CommentConfirmed
+}
+
+// IsCounted reports whether the comment counts towards its blog's comment
+// count.
+func (c *Comment) IsCounted() bool {
+	return c.IsConfirmed() && !c.Deleted
+}
+
+// ReplyTo makes c a reply to parent, enforcing that both belong to the same
+// blog and that the thread doesn't grow deeper than maxDepth.
+func (c *Comment) ReplyTo(parent *Comment, maxDepth int) error {
+	if parent.BlogId != c.BlogId {
+		return InvalidField("parent_id", "parent comment %d belongs to a different blog", parent.Id)
+	}
+	if parent.Deleted {
+		return FailedPrecondition("can't reply to deleted comment %d", parent.Id)
+	}
+	if !parent.IsConfirmed() {
+		return FailedPrecondition("can't reply to comment %d before it's confirmed", parent.Id)
+	}
+	if parent.Depth+1 > maxDepth {
+		return FailedPrecondition("replies can't be nested deeper than %d levels", maxDepth)
+	}
+	parentId := parent.Id
+	c.ParentId = &parentId
+	c.Depth = parent.Depth + 1
 	return nil
 }
+
+// CommentNode is a comment together with its replies.
+type CommentNode struct {
+	Comment Comment        `json:"comment"`
+	Replies []*CommentNode `json:"replies"`
+}
+
+// BuildCommentTree arranges comments into threads, oldest first at every
+// level. Comments whose parent isn't among them become roots.
+func BuildCommentTree(comments []Comment) []*CommentNode {
+	sorted := make([]Comment, len(comments))
+	copy(sorted, comments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	nodes := make(map[int]*CommentNode, len(sorted))
+	for _, comment := range sorted {
+		nodes[comment.Id] = &CommentNode{Comment: comment, Replies: []*CommentNode{}}
+	}
+
+	roots := []*CommentNode{}
+	for _, comment := range sorted {
+		node := nodes[comment.Id]
+		if comment.ParentId != nil {
+			if parent, ok := nodes[*comment.ParentId]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// FlattenCommentTree lists the comments of a tree in depth-first order.
+func FlattenCommentTree(roots []*CommentNode) []Comment {
+	comments := []Comment{}
+	var visit func(nodes []*CommentNode)
+	visit = func(nodes []*CommentNode) {
+		for _, node := range nodes {
+			comments = append(comments, node.Comment)
+			visit(node.Replies)
+		}
+	}
+	visit(roots)
+	return comments
+}
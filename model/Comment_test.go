@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommentReplyTo(t *testing.T) {
+	parent := &Comment{Id: 1, BlogId: 10, Depth: 1}
+	reply := &Comment{BlogId: 10}
+
+	if err := reply.ReplyTo(parent, 5); err != nil {
+		t.Fatalf("ReplyTo: %v", err)
+	}
+	if reply.ParentId == nil || *reply.ParentId != 1 || reply.Depth != 2 {
+		t.Errorf("reply = %+v, want parent 1 at depth 2", reply)
+	}
+}
+
+func TestCommentReplyToRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent Comment
+		want   ErrorKind
+	}{
+		{"other blog", Comment{Id: 1, BlogId: 11}, KindInvalidArgument},
+		{"deleted parent", Comment{Id: 1, BlogId: 10, Deleted: true}, KindFailedPrecondition},
+		{"pending parent", Comment{Id: 1, BlogId: 10, SagaState: CommentPending}, KindFailedPrecondition},
+		{"compensated parent", Comment{Id: 1, BlogId: 10, SagaState: CommentCompensated}, KindFailedPrecondition},
+		{"too deep", Comment{Id: 1, BlogId: 10, Depth: 3}, KindFailedPrecondition},
+	}
+	for _, test := range tests {
+		reply := &Comment{BlogId: 10}
+		err := reply.ReplyTo(&test.parent, 3)
+		if KindOf(err) != test.want {
+			t.Errorf("%s: got %v, want kind %v", test.name, err, test.want)
+		}
+		if reply.ParentId != nil {
+			t.Errorf("%s: reply was attached anyway", test.name)
+		}
+	}
+}
+
+func TestBuildCommentTree(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2024, 5, 1, 12, minute, 0, 0, time.UTC) }
+	parent := func(id int) *int { return &id }
+	comments := []Comment{
+		{Id: 4, ParentId: parent(1), Depth: 1, CreatedAt: at(4)},
+		{Id: 2, CreatedAt: at(2)},
+		{Id: 3, ParentId: parent(1), Depth: 1, CreatedAt: at(3)},
+		{Id: 1, CreatedAt: at(1)},
+		{Id: 5, ParentId: parent(3), Depth: 2, CreatedAt: at(5)},
+		// Its parent isn't in the list, so it becomes a root.
+		{Id: 6, ParentId: parent(99), Depth: 1, CreatedAt: at(6)},
+	}
+
+	roots := BuildCommentTree(comments)
+	if len(roots) != 3 || roots[0].Comment.Id != 1 || roots[1].Comment.Id != 2 || roots[2].Comment.Id != 6 {
+		t.Fatalf("roots = %v", ids(FlattenCommentTree(roots)))
+	}
+	if replies := roots[0].Replies; len(replies) != 2 || replies[0].Comment.Id != 3 || replies[1].Comment.Id != 4 {
+		t.Errorf("replies to 1 are out of order")
+	}
+
+	got := ids(FlattenCommentTree(roots))
+	want := []int{1, 3, 5, 4, 2, 6}
+	if len(got) != len(want) {
+		t.Fatalf("flattened = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("flattened = %v, want %v", got, want)
+		}
+	}
+}
+
+func ids(comments []Comment) []int {
+	result := make([]int, len(comments))
+	for i, comment := range comments {
+		result[i] = comment.Id
+	}
+	return result
+}
+
+func TestCommentIsCounted(t *testing.T) {
+	tests := []struct {
+		comment Comment
+		want    bool
+	}{
+		{Comment{}, true},
+		{Comment{SagaState: CommentConfirmed}, true},
+		{Comment{SagaState: CommentPending}, false},
+		{Comment{SagaState: CommentCompensated}, false},
+		{Comment{SagaState: CommentConfirmed, Deleted: true}, false},
+	}
+	for _, test := range tests {
+		if got := test.comment.IsCounted(); got != test.want {
+			t.Errorf("IsCounted() of %+v = %v, want %v", test.comment, got, test.want)
+		}
+	}
+}
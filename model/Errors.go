@@ -0,0 +1,93 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorKind classifies domain errors by what the caller can do about them.
+type ErrorKind int
+
+const (
+	KindNotFound ErrorKind = iota + 1
+	KindInvalidArgument
+	KindFailedPrecondition
+	KindPermissionDenied
+	KindConflict
+	KindUnauthenticated
+)
+
+// FieldViolation describes why one field of a request is invalid.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// DomainError is an error the model or service layer raises on purpose, as
+// opposed to an infrastructure failure.
+type DomainError struct {
+	Kind       ErrorKind
+	Message    string
+	Violations []FieldViolation
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return &DomainError{Kind: KindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func InvalidArgument(format string, args ...interface{}) error {
+	return &DomainError{Kind: KindInvalidArgument, Message: fmt.Sprintf(format, args...)}
+}
+
+func FailedPrecondition(format string, args ...interface{}) error {
+	return &DomainError{Kind: KindFailedPrecondition, Message: fmt.Sprintf(format, args...)}
+}
+
+func PermissionDenied(format string, args ...interface{}) error {
+	return &DomainError{Kind: KindPermissionDenied, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return &DomainError{Kind: KindConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func Unauthenticated(format string, args ...interface{}) error {
+	return &DomainError{Kind: KindUnauthenticated, Message: fmt.Sprintf(format, args...)}
+}
+
+// InvalidField reports a single invalid field.
+func InvalidField(field string, format string, args ...interface{}) error {
+	return ValidationError([]FieldViolation{{Field: field, Description: fmt.Sprintf(format, args...)}})
+}
+
+// ValidationError reports every invalid field of a request at once. It
+// returns nil when there are no violations.
+func ValidationError(violations []FieldViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(violations))
+	for i, violation := range violations {
+		descriptions[i] = violation.Description
+	}
+	return &DomainError{
+		Kind:       KindInvalidArgument,
+		Message:    strings.Join(descriptions, "; "),
+		Violations: violations,
+	}
+}
+
+// KindOf returns the kind of a domain error anywhere in err's chain, or zero
+// if err isn't one.
+func KindOf(err error) ErrorKind {
+	var domainError *DomainError
+	if errors.As(err, &domainError) {
+		return domainError.Kind
+	}
+	return 0
+}
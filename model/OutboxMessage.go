@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// OutboxMessage is an event waiting to be published. It's stored together
+// with the change that raised it and removed from the backlog once sent.
+type OutboxMessage struct {
+	Id        int        `json:"id"`
+	Subject   string     `json:"subject"`
+	Version   int        `json:"version"`
+	Payload   []byte     `json:"payload"`
+	CreatedAt time.Time  `json:"createdAt"`
+	SentAt    *time.Time `json:"sentAt,omitempty"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"lastError,omitempty"`
+	// TraceContext links the published event to the request that raised it.
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+}
@@ -24,24 +24,79 @@ func NewVote(userId int64, voteType VoteType) *Vote {
 }
 
 func (v *Vote) Validate() error {
+	var violations []FieldViolation
+
 	if v.UserId <= 0 {
-		return fmt.Errorf("user ID must be a positive integer")
+		violations = append(violations, FieldViolation{Field: "user_id", Description: "user ID must be a positive integer"})
 	}
 
 	if v.VoteType != Downvote && v.VoteType != Upvote {
-		return fmt.Errorf("invalid vote type: %s, allowed values are 'DOWNVOTE' or 'UPVOTE'", v.VoteType)
+		violations = append(violations, FieldViolation{Field: "vote_type", Description: fmt.Sprintf("invalid vote type: %s, allowed values are 'DOWNVOTE' or 'UPVOTE'", v.VoteType)})
+	}
+
+	return ValidationError(violations)
+}
+
+// VoteTally holds the vote counters kept on a blog.
+type VoteTally struct {
+	VoteCount     int64
+	UpvoteCount   int64
+	DownvoteCount int64
+}
+
+// TallyOf counts votes from scratch.
+func TallyOf(votes []Vote) VoteTally {
+	var tally VoteTally
+	for _, vote := range votes {
+		voteType := vote.VoteType
+		tally = tally.Add(TallyChange(nil, &voteType))
+	}
+	return tally
+}
+
+// TallyChange is the change to a blog's counters when a user's vote goes from
+// previous to current. A nil type means no vote.
+func TallyChange(previous *VoteType, current *VoteType) VoteTally {
+	var change VoteTally
+	if previous != nil {
+		change = change.Add(voteWeight(*previous).negate())
 	}
+	if current != nil {
+		change = change.Add(voteWeight(*current))
+	}
+	return change
+}
+
+func (t VoteTally) Add(other VoteTally) VoteTally {
+	return VoteTally{
+		VoteCount:     t.VoteCount + other.VoteCount,
+		UpvoteCount:   t.UpvoteCount + other.UpvoteCount,
+		DownvoteCount: t.DownvoteCount + other.DownvoteCount,
+	}
+}
 
-	return nil
+func (t VoteTally) IsZero() bool {
+	return t == VoteTally{}
+}
+
+func (t VoteTally) negate() VoteTally {
+	return VoteTally{VoteCount: -t.VoteCount, UpvoteCount: -t.UpvoteCount, DownvoteCount: -t.DownvoteCount}
+}
+
+func voteWeight(voteType VoteType) VoteTally {
+	if voteType == Upvote {
+		return VoteTally{VoteCount: 1, UpvoteCount: 1}
+	}
+	return VoteTally{VoteCount: -1, DownvoteCount: 1}
 }
 
-func ParseVoteType(vote string) VoteType {
+func ParseVoteType(vote string) (VoteType, error) {
 	switch vote {
 	case string(Downvote):
-		return Downvote
+		return Downvote, nil
 	case string(Upvote):
-		return Upvote
+		return Upvote, nil
 	default:
-		return Upvote
+		return "", InvalidField("vote_type", "invalid vote type: %s, allowed values are 'DOWNVOTE' or 'UPVOTE'", vote)
 	}
 }
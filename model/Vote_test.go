@@ -0,0 +1,61 @@
+package model
+
+import "testing"
+
+func TestTallyChange(t *testing.T) {
+	upvote, downvote := Upvote, Downvote
+	tests := []struct {
+		name              string
+		previous, current *VoteType
+		want              VoteTally
+	}{
+		{"first upvote", nil, &upvote, VoteTally{VoteCount: 1, UpvoteCount: 1}},
+		{"first downvote", nil, &downvote, VoteTally{VoteCount: -1, DownvoteCount: 1}},
+		{"same vote again", &upvote, &upvote, VoteTally{}},
+		{"upvote to downvote", &upvote, &downvote, VoteTally{VoteCount: -2, UpvoteCount: -1, DownvoteCount: 1}},
+		{"downvote to upvote", &downvote, &upvote, VoteTally{VoteCount: 2, UpvoteCount: 1, DownvoteCount: -1}},
+		{"retract upvote", &upvote, nil, VoteTally{VoteCount: -1, UpvoteCount: -1}},
+		{"retract downvote", &downvote, nil, VoteTally{VoteCount: 1, DownvoteCount: -1}},
+		{"retract nothing", nil, nil, VoteTally{}},
+	}
+	for _, test := range tests {
+		if got := TallyChange(test.previous, test.current); got != test.want {
+			t.Errorf("%s: TallyChange = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestTallyChangeAddsUpToTallyOf replays a user changing and retracting
+// their vote and checks the running tally against a recount.
+func TestTallyChangeAddsUpToTallyOf(t *testing.T) {
+	upvote, downvote := Upvote, Downvote
+	others := []Vote{{UserId: 2, VoteType: Upvote}, {UserId: 3, VoteType: Downvote}, {UserId: 4, VoteType: Upvote}}
+
+	tally := TallyOf(others)
+	var previous *VoteType
+	for _, current := range []*VoteType{&upvote, &downvote, nil, &downvote, &upvote} {
+		tally = tally.Add(TallyChange(previous, current))
+		previous = current
+
+		votes := append([]Vote{}, others...)
+		if current != nil {
+			votes = append(votes, Vote{UserId: 1, VoteType: *current})
+		}
+		if want := TallyOf(votes); tally != want {
+			t.Fatalf("after voting %v: tally %+v, recount %+v", current, tally, want)
+		}
+	}
+}
+
+func TestParseVoteType(t *testing.T) {
+	for _, vote := range []string{"UPVOTE", "DOWNVOTE"} {
+		if voteType, err := ParseVoteType(vote); err != nil || string(voteType) != vote {
+			t.Errorf("ParseVoteType(%q) = %q, %v", vote, voteType, err)
+		}
+	}
+	for _, vote := range []string{"", "upvote", "SIDEVOTE"} {
+		if _, err := ParseVoteType(vote); KindOf(err) != KindInvalidArgument {
+			t.Errorf("ParseVoteType(%q) = %v, want an invalid argument", vote, err)
+		}
+	}
+}
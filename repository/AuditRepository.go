@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"BlogApplication/config"
+	"BlogApplication/logging"
+	"BlogApplication/model"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type AuditRepository struct {
+	Collection *mongo.Collection
+	Timeout    time.Duration
+	Logger     *slog.Logger
+}
+
+func NewAuditRepository(client *mongo.Client, cfg config.MongoConfig, logger *slog.Logger) *AuditRepository {
+	database := client.Database(cfg.Database)
+	collection := database.Collection("audit_log")
+	return &AuditRepository{
+		Collection: collection,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
+	}
+}
+
+func (repository *AuditRepository) Create(ctx context.Context, entry *model.AuditEntry) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Create")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(attribute.String("audit.action", entry.Action), attribute.Int64("audit.resource_id", entry.ResourceId))
+
+	_, err := repository.Collection.InsertOne(ctx, entry)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
+		span.SetStatus(codes.Error, "Create failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "Create successful")
+	return nil
+}
@@ -1,28 +1,42 @@
 package repository
 
 import (
+	"BlogApplication/config"
+	"BlogApplication/logging"
 	"BlogApplication/model"
+	"BlogApplication/telemetry"
+	"BlogApplication/useCases"
 	"context"
-	"encoding/json"
-	"fmt"
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
+// blogCounterFields are the blog fields maintained atomically by the
+// repository rather than written from the model.
+var blogCounterFields = []string{"commentcount", "votecount", "upvotecount", "downvotecount"}
+
 type BlogRepository struct {
 	Collection *mongo.Collection
+	Counters   *CounterRepository
+	Timeout    time.Duration
+	Logger     *slog.Logger
 }
 
-func NewBlogRepository(client *mongo.Client) *BlogRepository {
-	database := client.Database("soa")
+func NewBlogRepository(client *mongo.Client, cfg config.MongoConfig, counters *CounterRepository, logger *slog.Logger) *BlogRepository {
+	database := client.Database(cfg.Database)
 	collection := database.Collection("blogs")
 	return &BlogRepository{
 		Collection: collection,
+		Counters:   counters,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
 	}
 }
 
@@ -30,11 +44,14 @@ func (repository *BlogRepository) Delete(ctx context.Context, id int64) error {
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "Delete")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(id))
 
-	_, err := repository.Collection.DeleteOne(context.Background(), bson.M{"id": id})
+	_, err := repository.Collection.DeleteOne(ctx, bson.M{"id": id})
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "Delete failed", "error", err)
 		span.SetStatus(codes.Error, "Delete failed")
 		return err
 	}
@@ -42,236 +59,451 @@ func (repository *BlogRepository) Delete(ctx context.Context, id int64) error {
 	span.SetStatus(codes.Ok, "Delete successful")
 	return nil
 }
-func (repository *BlogRepository) UpdateVotes(ctx context.Context, blogID int, votes *[]model.Vote) error {
+func (repository *BlogRepository) Find(ctx context.Context, id int64) (model.Blog, error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "UpdateVotes")
+	ctx, span := tracer.Start(ctx, "Find")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	reqData, jsonError := json.Marshal(votes)
-	if jsonError != nil {
-		span.RecordError(jsonError)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return jsonError
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(id))
 
-	filter := bson.M{"id": blogID}
-	update := bson.M{"$set": bson.M{"votes": votes}}
-	_, err := repository.Collection.UpdateOne(context.Background(), filter, update)
+	var blog model.Blog
+	err := repository.Collection.FindOne(ctx, bson.M{"id": id}).Decode(&blog)
 	if err != nil {
-		span.SetStatus(codes.Error, "UpdateVotes failed")
-		return err
+		span.SetStatus(codes.Error, "Find failed")
+		return model.Blog{}, notFoundOr(err, "blog with id %d not found", id)
 	}
 
-	span.SetStatus(codes.Ok, "UpdateVotes successful")
-	return nil
+	span.SetStatus(codes.Ok, "Find successful")
+	return blog, nil
 }
 
-func (repository *BlogRepository) SetVote(ctx context.Context, b *model.Blog, userID int64, voteType model.VoteType) error {
+func (repository *BlogRepository) FindAllPublishedPage(ctx context.Context, viewer model.BlogViewer, page useCases.PageRequest) (*useCases.PagedResult[model.Blog], error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "SetVote")
+	ctx, span := tracer.Start(ctx, "FindAllPublishedPage")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.PageSize(page.Limit()))
 
-	var existingVote *model.Vote
-	for _, vote := range b.Votes {
-		if vote.UserId == userID {
-			existingVote = &vote
-			break
-		}
+	result, err := findPage(ctx, repository.Collection, visibleTo(viewer), "date", page, blogCursor)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "FindAllPublishedPage failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllPublishedPage failed")
+		return nil, err
 	}
 
-	if existingVote != nil {
+	span.SetStatus(codes.Ok, "FindAllPublishedPage successful")
+	return result, nil
+}
 
-		if existingVote.VoteType != voteType {
-			existingVote.VoteType = voteType
+func (repository *BlogRepository) FindAllByAuthorPage(ctx context.Context, id int64, viewer model.BlogViewer, page useCases.PageRequest) (*useCases.PagedResult[model.Blog], error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindAllByAuthorPage")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-			err := repository.UpdateVotes(ctx, b.Id, &b.Votes)
-			if err != nil {
-				span.SetStatus(codes.Error, "SetVote failed")
-				return fmt.Errorf("error updating votes: %w", err)
-			}
-			b.UpdateBlogStatus()
-		}
-		return nil
+	span.SetAttributes(telemetry.UserId(id))
+
+	filter := bson.M{"$and": bson.A{bson.M{"authorid": id}, visibleTo(viewer)}}
+	result, err := findPage(ctx, repository.Collection, filter, "date", page, blogCursor)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "FindAllByAuthorPage failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllByAuthorPage failed")
+		return nil, err
 	}
 
-	b.Votes = append(b.Votes, model.Vote{UserId: userID, VoteType: voteType})
-	err := repository.UpdateVotes(ctx, b.Id, &b.Votes)
+	span.SetStatus(codes.Ok, "FindAllByAuthorPage successful")
+	return result, nil
+}
+
+func (repository *BlogRepository) FindAllByTopicPage(ctx context.Context, topicType model.BlogTopicType, viewer model.BlogViewer, page useCases.PageRequest) (*useCases.PagedResult[model.Blog], error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindAllByTopicPage")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.Topic(string(topicType)))
+
+	filter := bson.M{"$and": bson.A{bson.M{"blogtopic": topicType}, visibleTo(viewer)}}
+	result, err := findPage(ctx, repository.Collection, filter, "date", page, blogCursor)
 	if err != nil {
-		span.SetStatus(codes.Error, "SetVote failed")
-		return fmt.Errorf("error adding vote: %w", err)
+		repository.Logger.DebugContext(ctx, "FindAllByTopicPage failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllByTopicPage failed")
+		return nil, err
 	}
-	b.UpdateBlogStatus()
 
-	span.SetStatus(codes.Ok, "SetVote successful")
+	span.SetStatus(codes.Ok, "FindAllByTopicPage successful")
+	return result, nil
+}
+
+func (repository *BlogRepository) Create(ctx context.Context, blog *model.Blog) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Create")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.UserId(blog.AuthorId), telemetry.Topic(string(blog.BlogTopic)), telemetry.Status(string(blog.Status)))
+
+	id, err := repository.NextId(ctx)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
+		span.SetStatus(codes.Error, "Create failed")
+		return err
+	}
+	blog.Id = id
+	blog.Date = time.Now()
+	_, err = repository.Collection.InsertOne(ctx, blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "Create failed")
+		return conflictOr(err, "blog with id %d", blog.Id)
+	}
+	span.SetStatus(codes.Ok, "Create successful")
 	return nil
 }
 
-func (repository *BlogRepository) Find(ctx context.Context, id int64) (model.Blog, error) {
+func (repository *BlogRepository) Update(ctx context.Context, blog *model.Blog) error {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "Find")
+	ctx, span := tracer.Start(ctx, "Update")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(int64(blog.Id)), telemetry.Status(string(blog.Status)))
 
-	var blog model.Blog
-	err := repository.Collection.FindOne(context.Background(), bson.M{"id": id}).Decode(&blog)
+	fields, err := toDocument(blog)
 	if err != nil {
-		span.SetStatus(codes.Error, "Find failed")
-		return model.Blog{}, err
+		repository.Logger.DebugContext(ctx, "Update failed", "error", err)
+		span.SetStatus(codes.Error, "Update failed")
+		return err
+	}
+	// Counters are only ever changed with $inc, so a stale copy of the blog
+	// must not overwrite them.
+	for _, counter := range blogCounterFields {
+		delete(fields, counter)
 	}
 
-	span.SetStatus(codes.Ok, "Find successful")
-	return blog, nil
+	filter := bson.M{"id": blog.Id}
+	update := bson.M{"$set": fields}
+	_, err = repository.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Update failed", "error", err)
+		span.SetStatus(codes.Error, "Update failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "Update successful")
+	return nil
+}
+
+func (repository *BlogRepository) NextId(ctx context.Context) (int, error) {
+	return repository.Counters.NextId(ctx, BlogCounter)
 }
 
-func (repository *BlogRepository) FindAllPublished(ctx context.Context) ([]model.Blog, error) {
+func (repository *BlogRepository) FindScheduledDue(ctx context.Context, now time.Time) ([]model.Blog, error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "FindAllPublished")
+	ctx, span := tracer.Start(ctx, "FindScheduledDue")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{}"))
+	span.SetAttributes(attribute.String("blog.publish_due_before", now.Format(time.RFC3339)))
 
 	var blogs = make([]model.Blog, 0)
-	cur, err := repository.Collection.Find(context.Background(), bson.M{})
+	filter := bson.M{"status": model.Draft, "publishat": bson.M{"$lte": now}}
+	cur, err := repository.Collection.Find(ctx, filter)
 	if err != nil {
-		span.SetStatus(codes.Error, "FindAllPublished failed")
+		repository.Logger.DebugContext(ctx, "FindScheduledDue failed", "error", err)
+		span.SetStatus(codes.Error, "FindScheduledDue failed")
 		return nil, err
 	}
-	defer cur.Close(context.Background())
-	for cur.Next(context.Background()) {
-		var blog model.Blog
-		err := cur.Decode(&blog)
-		if err != nil {
-			span.SetStatus(codes.Error, "FindAllPublished failed")
-			return nil, err
-		}
-		blogs = append(blogs, blog)
+	if err := cur.All(ctx, &blogs); err != nil {
+		repository.Logger.DebugContext(ctx, "FindScheduledDue failed", "error", err)
+		span.SetStatus(codes.Error, "FindScheduledDue failed")
+		return nil, err
 	}
 
-	span.SetStatus(codes.Ok, "FindAllPublished successful")
+	span.SetStatus(codes.Ok, "FindScheduledDue successful")
 	return blogs, nil
 }
 
-func (repository *BlogRepository) FindAllByAuthor(ctx context.Context, id int64) ([]model.Blog, error) {
+// PublishScheduled stores a blog published by the scheduler, but only while
+// it is still a due draft. It reports false when another instance already
+// published it or the author unscheduled it in the meantime.
+func (repository *BlogRepository) PublishScheduled(ctx context.Context, blog *model.Blog, now time.Time) (bool, error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "FindAllByAuthor")
+	ctx, span := tracer.Start(ctx, "PublishScheduled")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(int64(blog.Id)))
 
-	var blogs = make([]model.Blog, 0)
-	cur, err := repository.Collection.Find(context.Background(), bson.M{"authorid": id})
+	filter := bson.M{"id": blog.Id, "status": model.Draft, "publishat": bson.M{"$lte": now}}
+	update := bson.M{
+		"$set":   bson.M{"status": blog.Status, "date": blog.Date},
+		"$unset": bson.M{"publishat": ""},
+	}
+	result, err := repository.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		span.SetStatus(codes.Error, "FindAllByAuthor failed")
-		return nil, err
+		repository.Logger.DebugContext(ctx, "PublishScheduled failed", "error", err)
+		span.SetStatus(codes.Error, "PublishScheduled failed")
+		return false, err
 	}
-	defer cur.Close(context.Background())
-	for cur.Next(context.Background()) {
-		var blog model.Blog
-		err := cur.Decode(&blog)
-		if err != nil {
-			span.SetStatus(codes.Error, "FindAllByAuthor failed")
-			return nil, err
-		}
-		blogs = append(blogs, blog)
+
+	span.SetStatus(codes.Ok, "PublishScheduled successful")
+	return result.ModifiedCount > 0, nil
+}
+
+// IncrementCommentCount atomically adds delta to the blog's comment count and
+// returns the blog as it is after the change.
+func (repository *BlogRepository) IncrementCommentCount(ctx context.Context, blogID int64, delta int64) (model.Blog, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "IncrementCommentCount")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(blogID), attribute.Int64("blog.comment_count.delta", delta))
+
+	var blog model.Blog
+	filter := bson.M{"id": blogID}
+	update := bson.M{"$inc": bson.M{"commentcount": delta}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := repository.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "IncrementCommentCount failed")
+		return model.Blog{}, notFoundOr(err, "blog with id %d not found", blogID)
 	}
 
-	span.SetStatus(codes.Ok, "FindAllByAuthor successful")
-	return blogs, nil
+	span.SetStatus(codes.Ok, "IncrementCommentCount successful")
+	return blog, nil
 }
 
-func (repository *BlogRepository) FindAllByTopic(ctx context.Context, topicType model.BlogTopicType) ([]model.Blog, error) {
+func (repository *BlogRepository) UpdateStatus(ctx context.Context, blogID int64, status model.BlogStatus) error {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "FindAllByTopic")
+	ctx, span := tracer.Start(ctx, "UpdateStatus")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"topic\": "+string(topicType)+" }"))
+	span.SetAttributes(telemetry.BlogId(blogID), telemetry.Status(string(status)))
+
+	filter := bson.M{"id": blogID}
+	update := bson.M{"$set": bson.M{"status": status}}
+	_, err := repository.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "UpdateStatus failed", "error", err)
+		span.SetStatus(codes.Error, "UpdateStatus failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "UpdateStatus successful")
+	return nil
+}
+
+// FindMissingCommentCount returns blogs stored before comment counts were
+// tracked on the blog document.
+func (repository *BlogRepository) FindMissingCommentCount(ctx context.Context) ([]model.Blog, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindMissingCommentCount")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
 	var blogs = make([]model.Blog, 0)
-	cur, err := repository.Collection.Find(context.Background(), bson.M{"blogtopic": topicType})
+	cur, err := repository.Collection.Find(ctx, bson.M{"commentcount": bson.M{"$exists": false}})
 	if err != nil {
-		span.SetStatus(codes.Error, "FindAllByTopic failed")
+		repository.Logger.DebugContext(ctx, "FindMissingCommentCount failed", "error", err)
+		span.SetStatus(codes.Error, "FindMissingCommentCount failed")
 		return nil, err
 	}
-	defer cur.Close(context.Background())
-	for cur.Next(context.Background()) {
-		var blog model.Blog
-		err := cur.Decode(&blog)
-		if err != nil {
-			span.SetStatus(codes.Error, "FindAllByTopic failed")
-			return nil, err
-		}
-		blogs = append(blogs, blog)
+	if err := cur.All(ctx, &blogs); err != nil {
+		repository.Logger.DebugContext(ctx, "FindMissingCommentCount failed", "error", err)
+		span.SetStatus(codes.Error, "FindMissingCommentCount failed")
+		return nil, err
 	}
 
-	span.SetStatus(codes.Ok, "FindAllByTopic successful")
+	span.SetStatus(codes.Ok, "FindMissingCommentCount successful")
 	return blogs, nil
 }
 
-func (repository *BlogRepository) Create(ctx context.Context, blog *model.Blog) error {
+// SetCommentCount initializes the comment count of a blog that doesn't track
+// one yet. Blogs that already have a count are left untouched.
+func (repository *BlogRepository) SetCommentCount(ctx context.Context, blogID int64, count int64) error {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "Create")
+	ctx, span := tracer.Start(ctx, "SetCommentCount")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	reqData, jsonError := json.Marshal(blog)
-	if jsonError != nil {
-		span.RecordError(jsonError)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return jsonError
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(blogID), attribute.Int64("blog.comment_count", count))
 
-	blog.Id = repository.NextId(ctx)
-	blog.Date = time.Now()
-	_, err := repository.Collection.InsertOne(context.Background(), blog)
+	filter := bson.M{"id": blogID, "commentcount": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"commentcount": count}}
+	_, err := repository.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		span.SetStatus(codes.Error, "Create failed")
+		repository.Logger.DebugContext(ctx, "SetCommentCount failed", "error", err)
+		span.SetStatus(codes.Error, "SetCommentCount failed")
 		return err
 	}
-	span.SetStatus(codes.Ok, "Create successful")
+
+	span.SetStatus(codes.Ok, "SetCommentCount successful")
 	return nil
 }
 
-func (repository *BlogRepository) Update(ctx context.Context, blog *model.Blog) error {
+func toDocument(value interface{}) (bson.M, error) {
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var document bson.M
+	err = bson.Unmarshal(data, &document)
+	return document, err
+}
+
+// IncrementVoteCounts atomically applies change to the blog's vote counters
+// and returns the blog as it is after the change.
+func (repository *BlogRepository) IncrementVoteCounts(ctx context.Context, blogID int64, change model.VoteTally) (model.Blog, error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "Update")
+	ctx, span := tracer.Start(ctx, "IncrementVoteCounts")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	reqData, jsonError := json.Marshal(blog)
-	if jsonError != nil {
-		span.RecordError(jsonError)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return jsonError
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	var blog model.Blog
+	filter := bson.M{"id": blogID}
+	update := bson.M{"$inc": bson.M{
+		"votecount":     change.VoteCount,
+		"upvotecount":   change.UpvoteCount,
+		"downvotecount": change.DownvoteCount,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := repository.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "IncrementVoteCounts failed")
+		return model.Blog{}, notFoundOr(err, "blog with id %d not found", blogID)
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
 
-	filter := bson.M{"id": blog.Id}
-	update := bson.M{"$set": blog}
-	_, err := repository.Collection.UpdateOne(context.Background(), filter, update)
+	span.SetStatus(codes.Ok, "IncrementVoteCounts successful")
+	return blog, nil
+}
+
+// EmbeddedVotes are the votes a blog document stored inline before votes got
+// their own collection.
+type EmbeddedVotes struct {
+	BlogId int          `bson:"id"`
+	Votes  []model.Vote `bson:"votes"`
+}
+
+func (repository *BlogRepository) FindEmbeddedVotes(ctx context.Context) ([]EmbeddedVotes, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindEmbeddedVotes")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	var blogs = make([]EmbeddedVotes, 0)
+	filter := bson.M{"votes": bson.M{"$exists": true}}
+	opts := options.Find().SetProjection(bson.M{"id": 1, "votes": 1})
+	cur, err := repository.Collection.Find(ctx, filter, opts)
 	if err != nil {
-		span.SetStatus(codes.Error, "Update failed")
-		return err
+		repository.Logger.DebugContext(ctx, "FindEmbeddedVotes failed", "error", err)
+		span.SetStatus(codes.Error, "FindEmbeddedVotes failed")
+		return nil, err
+	}
+	if err := cur.All(ctx, &blogs); err != nil {
+		repository.Logger.DebugContext(ctx, "FindEmbeddedVotes failed", "error", err)
+		span.SetStatus(codes.Error, "FindEmbeddedVotes failed")
+		return nil, err
 	}
 
-	span.SetStatus(codes.Ok, "Update successful")
-	return nil
+	span.SetStatus(codes.Ok, "FindEmbeddedVotes successful")
+	return blogs, nil
 }
 
-func (repository *BlogRepository) NextId(ctx context.Context) int {
+// ReplaceEmbeddedVotes drops the inline votes of a blog and sets its counters
+// to tally.
+func (repository *BlogRepository) ReplaceEmbeddedVotes(ctx context.Context, blogID int64, tally model.VoteTally) (model.Blog, error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "NextId")
+	ctx, span := tracer.Start(ctx, "ReplaceEmbeddedVotes")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	blogs, _ := repository.FindAllPublished(ctx)
+	span.SetAttributes(telemetry.BlogId(blogID))
 
-	maxId := 0
-	for _, blog := range blogs {
-		if blog.Id > maxId {
-			maxId = blog.Id
-		}
+	var blog model.Blog
+	filter := bson.M{"id": blogID}
+	update := bson.M{
+		"$set": bson.M{
+			"votecount":     tally.VoteCount,
+			"upvotecount":   tally.UpvoteCount,
+			"downvotecount": tally.DownvoteCount,
+		},
+		"$unset": bson.M{"votes": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := repository.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&blog)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "ReplaceEmbeddedVotes failed", "error", err)
+		span.SetStatus(codes.Error, "ReplaceEmbeddedVotes failed")
+		return model.Blog{}, err
+	}
+
+	span.SetStatus(codes.Ok, "ReplaceEmbeddedVotes successful")
+	return blog, nil
+}
+
+// BlogCount is how many blogs have a status and topic.
+type BlogCount struct {
+	Status model.BlogStatus    `bson:"status"`
+	Topic  model.BlogTopicType `bson:"blogtopic"`
+	Count  int64               `bson:"count"`
+}
+
+// CountByStatusAndTopic counts the blogs of every status and topic pair in a
+// single aggregation.
+func (repository *BlogRepository) CountByStatusAndTopic(ctx context.Context) ([]BlogCount, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "CountByStatusAndTopic")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"status": "$status", "blogtopic": "$blogtopic"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"status":    "$_id.status",
+			"blogtopic": "$_id.blogtopic",
+			"count":     1,
+		}}},
+	}
+
+	cur, err := repository.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "CountByStatusAndTopic failed", "error", err)
+		span.SetStatus(codes.Error, "CountByStatusAndTopic failed")
+		return nil, err
+	}
+	var counts = make([]BlogCount, 0)
+	if err := cur.All(ctx, &counts); err != nil {
+		repository.Logger.DebugContext(ctx, "CountByStatusAndTopic failed", "error", err)
+		span.SetStatus(codes.Error, "CountByStatusAndTopic failed")
+		return nil, err
 	}
 
-	span.SetStatus(codes.Ok, "NextId successful")
-	return maxId + 1
+	span.SetStatus(codes.Ok, "CountByStatusAndTopic successful")
+	return counts, nil
 }
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"BlogApplication/config"
+	"BlogApplication/logging"
+	"BlogApplication/model"
+	"BlogApplication/telemetry"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BlogRevisionRepository stores blog revisions. Revisions are append-only:
+// there is deliberately no way to update or delete one.
+type BlogRevisionRepository struct {
+	Collection *mongo.Collection
+	Counters   *CounterRepository
+	Timeout    time.Duration
+	Logger     *slog.Logger
+}
+
+func NewBlogRevisionRepository(client *mongo.Client, cfg config.MongoConfig, counters *CounterRepository, logger *slog.Logger) *BlogRevisionRepository {
+	database := client.Database(cfg.Database)
+	collection := database.Collection("blog_revisions")
+	return &BlogRevisionRepository{
+		Collection: collection,
+		Counters:   counters,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
+	}
+}
+
+func (repository *BlogRevisionRepository) Create(ctx context.Context, revision *model.BlogRevision) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Create")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(revision.BlogId))
+
+	id, err := repository.Counters.NextId(ctx, RevisionCounter)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
+		span.SetStatus(codes.Error, "Create failed")
+		return err
+	}
+	revision.Id = id
+	_, err = repository.Collection.InsertOne(ctx, revision)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
+		span.SetStatus(codes.Error, "Create failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "Create successful")
+	return nil
+}
+
+func (repository *BlogRevisionRepository) Find(ctx context.Context, blogID int64, id int64) (model.BlogRevision, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Find")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(blogID), telemetry.RevisionId(id))
+
+	var revision model.BlogRevision
+	err := repository.Collection.FindOne(ctx, bson.M{"blogid": blogID, "id": id}).Decode(&revision)
+	if err != nil {
+		span.SetStatus(codes.Error, "Find failed")
+		return model.BlogRevision{}, notFoundOr(err, "revision with id %d not found for blog %d", id, blogID)
+	}
+
+	span.SetStatus(codes.Ok, "Find successful")
+	return revision, nil
+}
+
+func (repository *BlogRevisionRepository) FindAllByBlog(ctx context.Context, blogID int64) ([]model.BlogRevision, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindAllByBlog")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	var revisions = make([]model.BlogRevision, 0)
+	opts := options.Find().SetSort(bson.D{{Key: "id", Value: -1}})
+	cur, err := repository.Collection.Find(ctx, bson.M{"blogid": blogID}, opts)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "FindAllByBlog failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllByBlog failed")
+		return nil, err
+	}
+	if err := cur.All(ctx, &revisions); err != nil {
+		repository.Logger.DebugContext(ctx, "FindAllByBlog failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllByBlog failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "FindAllByBlog successful")
+	return revisions, nil
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"BlogApplication/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// listedFilter matches blogs that are visible to everyone, mirroring
+// model.Blog.IsListed.
+func listedFilter() bson.M {
+	return bson.M{
+		"visibility": model.PublicBlog,
+		"status":     bson.M{"$in": model.ListedStatuses},
+	}
+}
+
+// visibleTo matches the blogs viewer may see, mirroring
+// model.Blog.IsVisibleTo.
+func visibleTo(viewer model.BlogViewer) bson.M {
+	switch {
+	case viewer.IncludeHidden:
+		return bson.M{}
+	case viewer.UserId > 0:
+		return bson.M{"$or": bson.A{listedFilter(), bson.M{"authorid": viewer.UserId}}}
+	default:
+		return listedFilter()
+	}
+}
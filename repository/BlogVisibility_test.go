@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"BlogApplication/model"
+	"context"
+	"testing"
+)
+
+// TestVisibleToMirrorsIsVisibleTo checks that the Mongo filter and the
+// in-memory check agree for every combination of status, visibility and
+// viewer.
+func TestVisibleToMirrorsIsVisibleTo(t *testing.T) {
+	client, cfg := testMongo(t)
+	ctx := context.Background()
+	collection := client.Database(cfg.Database).Collection("blogs")
+
+	var blogs []model.Blog
+	id := 0
+	for _, status := range []model.BlogStatus{model.Draft, model.Published, model.Active, model.Famous, model.Closed} {
+		for _, visibility := range []model.BlogVisibilityPolicy{model.PublicBlog, model.PrivateBlog} {
+			for _, authorId := range []int64{1, 2} {
+				id++
+				blogs = append(blogs, model.Blog{Id: id, Status: status, Visibility: visibility, AuthorId: authorId})
+			}
+		}
+	}
+	for _, blog := range blogs {
+		if _, err := collection.InsertOne(ctx, blog); err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+	}
+
+	viewers := []model.BlogViewer{{}, {UserId: 1}, {UserId: 3}, {UserId: 3, IncludeHidden: true}}
+	for _, viewer := range viewers {
+		cur, err := collection.Find(ctx, visibleTo(viewer))
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		var found []model.Blog
+		if err := cur.All(ctx, &found); err != nil {
+			t.Fatalf("All: %v", err)
+		}
+		matched := make(map[int]bool, len(found))
+		for _, blog := range found {
+			matched[blog.Id] = true
+		}
+		for _, blog := range blogs {
+			if matched[blog.Id] != blog.IsVisibleTo(viewer) {
+				t.Errorf("viewer %+v, %s %s blog by %d: filter matched %v, IsVisibleTo %v",
+					viewer, blog.Visibility, blog.Status, blog.AuthorId, matched[blog.Id], blog.IsVisibleTo(viewer))
+			}
+		}
+	}
+}
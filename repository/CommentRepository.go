@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"BlogApplication/config"
 	"BlogApplication/dto"
+	"BlogApplication/logging"
 	"BlogApplication/model"
+	"BlogApplication/telemetry"
+	"BlogApplication/useCases"
 	"context"
-	"encoding/json"
+	"log/slog"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,13 +20,19 @@ import (
 
 type CommentRepository struct {
 	Collection *mongo.Collection
+	Counters   *CounterRepository
+	Timeout    time.Duration
+	Logger     *slog.Logger
 }
 
-func NewCommentRepository(client *mongo.Client) *CommentRepository {
-	database := client.Database("soa")
+func NewCommentRepository(client *mongo.Client, cfg config.MongoConfig, counters *CounterRepository, logger *slog.Logger) *CommentRepository {
+	database := client.Database(cfg.Database)
 	collection := database.Collection("comments")
 	return &CommentRepository{
 		Collection: collection,
+		Counters:   counters,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
 	}
 }
 
@@ -29,14 +40,16 @@ func (repository *CommentRepository) FindById(ctx context.Context, id int) (mode
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "FindById")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.CommentId(int64(id)))
 
 	var comment model.Comment
-	err := repository.Collection.FindOne(context.Background(), bson.M{"id": id}).Decode(&comment)
+	err := repository.Collection.FindOne(ctx, bson.M{"id": id}).Decode(&comment)
 	if err != nil {
 		span.SetStatus(codes.Error, "FindById failed")
-		return model.Comment{}, err
+		return model.Comment{}, notFoundOr(err, "comment with id %d not found", id)
 	}
 
 	span.SetStatus(codes.Ok, "FindById successful")
@@ -47,21 +60,23 @@ func (repository *CommentRepository) Create(ctx context.Context, comment *model.
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "Create")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	reqData, jsonError := json.Marshal(comment)
-	if jsonError != nil {
-		span.RecordError(jsonError)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil, jsonError
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(comment.BlogId), telemetry.UserId(comment.AuthorId))
 
-	comment.Id = repository.NextId(ctx)
-	_, err := repository.Collection.InsertOne(context.Background(), comment)
+	id, err := repository.NextId(ctx)
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
 		span.SetStatus(codes.Error, "Create failed")
 		return nil, err
 	}
+	comment.Id = id
+	_, err = repository.Collection.InsertOne(ctx, comment)
+	if err != nil {
+		span.SetStatus(codes.Error, "Create failed")
+		return nil, conflictOr(err, "comment with id %d", comment.Id)
+	}
 
 	span.SetStatus(codes.Ok, "Create successful")
 	return comment, nil
@@ -71,19 +86,16 @@ func (repository *CommentRepository) Update(ctx context.Context, commentUpdate *
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "Update")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	reqData, jsonError := json.Marshal(commentUpdate)
-	if jsonError != nil {
-		span.RecordError(jsonError)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return jsonError
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.CommentId(commentUpdate.ID))
 
 	filter := bson.M{"id": commentUpdate.ID}
 	update := bson.M{"$set": bson.M{"text": commentUpdate.Text}}
-	_, err := repository.Collection.UpdateOne(context.Background(), filter, update)
+	_, err := repository.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "Update failed", "error", err)
 		span.SetStatus(codes.Error, "Update failed")
 		return err
 	}
@@ -96,12 +108,15 @@ func (repository *CommentRepository) Delete(ctx context.Context, id int64) error
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "Delete")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.CommentId(id))
 
 	filter := bson.M{"id": id}
-	_, err := repository.Collection.DeleteOne(context.Background(), filter)
+	_, err := repository.Collection.DeleteOne(ctx, filter)
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "Delete failed", "error", err)
 		span.SetStatus(codes.Error, "Delete failed")
 		return err
 	}
@@ -114,26 +129,29 @@ func (repository *CommentRepository) GetAll(ctx context.Context) ([]model.Commen
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "GetAll")
 	defer span.End()
-
-	span.SetAttributes(attribute.String("request.data", "{}"))
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
 	var comments = make([]model.Comment, 0)
-	cur, err := repository.Collection.Find(context.Background(), bson.M{})
+	cur, err := repository.Collection.Find(ctx, bson.M{})
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "GetAll failed", "error", err)
 		span.SetStatus(codes.Error, "GetAll failed")
 		return nil, err
 	}
-	defer cur.Close(context.Background())
-	for cur.Next(context.Background()) {
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
 		var comment model.Comment
 		err := cur.Decode(&comment)
 		if err != nil {
+			repository.Logger.DebugContext(ctx, "GetAll failed", "error", err)
 			span.SetStatus(codes.Error, "GetAll failed")
 			return nil, err
 		}
 		comments = append(comments, comment)
 	}
 	if err := cur.Err(); err != nil {
+		repository.Logger.DebugContext(ctx, "GetAll failed", "error", err)
 		span.SetStatus(codes.Error, "GetAll failed")
 		return nil, err
 	}
@@ -142,24 +160,48 @@ func (repository *CommentRepository) GetAll(ctx context.Context) ([]model.Commen
 	return comments, nil
 }
 
+func (repository *CommentRepository) GetAllPage(ctx context.Context, page useCases.PageRequest) (*useCases.PagedResult[model.Comment], error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "GetAllPage")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.PageSize(page.Limit()))
+
+	result, err := findPage(ctx, repository.Collection, bson.M{"sagastate": confirmedState()}, "createdat", page, commentCursor)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "GetAllPage failed", "error", err)
+		span.SetStatus(codes.Error, "GetAllPage failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "GetAllPage successful")
+	return result, nil
+}
+
 func (repository *CommentRepository) GetAllByBlog(ctx context.Context, id int64) ([]model.Comment, error) {
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "GetAllByBlog")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(id))
 
 	var comments = make([]model.Comment, 0)
-	cur, err := repository.Collection.Find(context.Background(), bson.M{"blogid": id})
+	cur, err := repository.Collection.Find(ctx, bson.M{"blogid": id})
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "GetAllByBlog failed", "error", err)
 		span.SetStatus(codes.Error, "GetAllByBlog failed")
 		return nil, err
 	}
-	defer cur.Close(context.Background())
-	for cur.Next(context.Background()) {
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
 		var comment model.Comment
 		err := cur.Decode(&comment)
 		if err != nil {
+			repository.Logger.DebugContext(ctx, "GetAllByBlog failed", "error", err)
 			span.SetStatus(codes.Error, "GetAllByBlog failed")
 			return nil, err
 		}
@@ -170,20 +212,202 @@ func (repository *CommentRepository) GetAllByBlog(ctx context.Context, id int64)
 	return comments, nil
 }
 
-func (repository *CommentRepository) NextId(ctx context.Context) int {
+func (repository *CommentRepository) GetAllByBlogPage(ctx context.Context, id int64, page useCases.PageRequest) (*useCases.PagedResult[model.Comment], error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "GetAllByBlogPage")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(id))
+
+	result, err := findPage(ctx, repository.Collection, bson.M{"blogid": id, "sagastate": confirmedState()}, "createdat", page, commentCursor)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "GetAllByBlogPage failed", "error", err)
+		span.SetStatus(codes.Error, "GetAllByBlogPage failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "GetAllByBlogPage successful")
+	return result, nil
+}
+
+func (repository *CommentRepository) NextId(ctx context.Context) (int, error) {
+	return repository.Counters.NextId(ctx, CommentCounter)
+}
+
+// FindThread loads a comment thread of a blog with a single aggregation:
+// the thread rooted at rootID, or every thread of the blog when rootID is
+// zero. Comments are returned unordered; see model.BuildCommentTree.
+func (repository *CommentRepository) FindThread(ctx context.Context, blogID int64, rootID int64) ([]model.Comment, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindThread")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(blogID), attribute.Int64("comment.root_id", rootID))
+
+	match := bson.M{"blogid": blogID, "sagastate": confirmedState()}
+	if rootID > 0 {
+		match["id"] = rootID
+	} else {
+		match["parentid"] = nil
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":                    repository.Collection.Name(),
+			"startWith":               "$id",
+			"connectFromField":        "id",
+			"connectToField":          "parentid",
+			"as":                      "replies",
+			"restrictSearchWithMatch": bson.M{"blogid": blogID, "sagastate": confirmedState()},
+		}}},
+	}
+
+	cur, err := repository.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "FindThread failed", "error", err)
+		span.SetStatus(codes.Error, "FindThread failed")
+		return nil, err
+	}
+	var roots []struct {
+		model.Comment `bson:",inline"`
+		Replies       []model.Comment `bson:"replies"`
+	}
+	if err := cur.All(ctx, &roots); err != nil {
+		repository.Logger.DebugContext(ctx, "FindThread failed", "error", err)
+		span.SetStatus(codes.Error, "FindThread failed")
+		return nil, err
+	}
+
+	var comments = make([]model.Comment, 0)
+	for _, root := range roots {
+		comments = append(comments, root.Comment)
+		comments = append(comments, root.Replies...)
+	}
+
+	span.SetStatus(codes.Ok, "FindThread successful")
+	return comments, nil
+}
+
+// CountReplies counts the replies to a comment that the creation saga let
+// through; pending and rolled back replies don't keep their parent around.
+func (repository *CommentRepository) CountReplies(ctx context.Context, id int64) (int64, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "CountReplies")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.CommentId(id))
+
+	count, err := repository.Collection.CountDocuments(ctx, bson.M{"parentid": id, "sagastate": confirmedState()})
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "CountReplies failed", "error", err)
+		span.SetStatus(codes.Error, "CountReplies failed")
+		return 0, err
+	}
+
+	span.SetStatus(codes.Ok, "CountReplies successful")
+	return count, nil
+}
+
+// Tombstone blanks out a comment's content while keeping its place in the
+// thread.
+func (repository *CommentRepository) Tombstone(ctx context.Context, id int64) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Tombstone")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.CommentId(id))
+
+	filter := bson.M{"id": id}
+	update := bson.M{"$set": bson.M{"text": model.DeletedCommentText, "deleted": true, "updatedat": time.Now()}}
+	_, err := repository.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Tombstone failed", "error", err)
+		span.SetStatus(codes.Error, "Tombstone failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "Tombstone successful")
+	return nil
+}
+
+// CountByBlog counts the comments of a blog, leaving out deleted and
+// unconfirmed ones.
+func (repository *CommentRepository) CountByBlog(ctx context.Context, blogID int64) (int64, error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "NextId")
+	ctx, span := tracer.Start(ctx, "CountByBlog")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	comments, _ := repository.GetAll(ctx)
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	filter := bson.M{"blogid": blogID, "deleted": bson.M{"$ne": true}, "sagastate": confirmedState()}
+	count, err := repository.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "CountByBlog failed", "error", err)
+		span.SetStatus(codes.Error, "CountByBlog failed")
+		return 0, err
+	}
+
+	span.SetStatus(codes.Ok, "CountByBlog successful")
+	return count, nil
+}
 
-	maxId := 0
-	for _, comment := range comments {
-		if comment.Id > maxId {
-			maxId = comment.Id
+// confirmedState matches the saga state of comments the creation saga let
+// through, including those stored before the saga existed.
+func confirmedState() bson.M {
+	return bson.M{"$nin": bson.A{model.CommentPending, model.CommentCompensated}}
+}
+
+// SetSagaState moves a comment to state if it's currently in one of from,
+// where an empty state stands for comments without one. It returns the
+// comment as it was before the call and whether its state changed.
+func (repository *CommentRepository) SetSagaState(ctx context.Context, id int64, from []model.CommentSagaState, state model.CommentSagaState) (model.Comment, bool, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "SetSagaState")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.CommentId(id), attribute.String("comment.saga_state", string(state)))
+
+	states := bson.A{}
+	for _, s := range from {
+		if s == "" {
+			states = append(states, nil)
+		} else {
+			states = append(states, s)
 		}
 	}
+	filter := bson.M{"id": id, "sagastate": bson.M{"$in": states}}
+	update := bson.M{"$set": bson.M{"sagastate": state}}
+
+	var previous model.Comment
+	err := repository.Collection.FindOneAndUpdate(ctx, filter, update).Decode(&previous)
+	if err == nil {
+		span.SetStatus(codes.Ok, "SetSagaState successful")
+		return previous, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		repository.Logger.DebugContext(ctx, "SetSagaState failed", "error", err)
+		span.SetStatus(codes.Error, "SetSagaState failed")
+		return model.Comment{}, false, err
+	}
 
-	span.SetStatus(codes.Ok, "NextId successful")
-	return maxId + 1
+	current, err := repository.FindById(ctx, int(id))
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "SetSagaState failed", "error", err)
+		span.SetStatus(codes.Error, "SetSagaState failed")
+		return model.Comment{}, false, err
+	}
+	span.SetStatus(codes.Ok, "SetSagaState successful")
+	return current, false, nil
 }
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"BlogApplication/model"
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestCountByBlogCountsWhatIsCounted checks that the stored count agrees
+// with model.Comment.IsCounted, comments stored before the saga included.
+func TestCountByBlogCountsWhatIsCounted(t *testing.T) {
+	client, cfg := testMongo(t)
+	ctx := context.Background()
+	comments := NewCommentRepository(client, cfg, NewCounterRepository(client, cfg, nil), nil)
+
+	stored := []model.Comment{
+		{Id: 1, BlogId: 1, Text: "before the saga"},
+		{Id: 2, BlogId: 1, Text: "confirmed", SagaState: model.CommentConfirmed},
+		{Id: 3, BlogId: 1, Text: "pending", SagaState: model.CommentPending},
+		{Id: 4, BlogId: 1, Text: "compensated", SagaState: model.CommentCompensated},
+		{Id: 5, BlogId: 1, Text: model.DeletedCommentText, SagaState: model.CommentConfirmed, Deleted: true},
+		{Id: 6, BlogId: 2, Text: "other blog"},
+	}
+	var want int64
+	for _, comment := range stored {
+		if _, err := comments.Collection.InsertOne(ctx, comment); err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+		if comment.BlogId == 1 && comment.IsCounted() {
+			want++
+		}
+	}
+
+	count, err := comments.CountByBlog(ctx, 1)
+	if err != nil {
+		t.Fatalf("CountByBlog: %v", err)
+	}
+	if count != want {
+		t.Errorf("CountByBlog = %d, want %d", count, want)
+	}
+}
+
+func TestSetCommentCountOnlyBackfills(t *testing.T) {
+	client, cfg := testMongo(t)
+	ctx := context.Background()
+	blogs := NewBlogRepository(client, cfg, NewCounterRepository(client, cfg, nil), nil)
+
+	// A blog stored before comment counts were tracked has no count at all.
+	if _, err := blogs.Collection.InsertOne(ctx, bson.M{"id": 1, "title": "Old"}); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	missing, err := blogs.FindMissingCommentCount(ctx)
+	if err != nil || len(missing) != 1 {
+		t.Fatalf("FindMissingCommentCount = %d blogs, %v; want 1", len(missing), err)
+	}
+
+	if err := blogs.SetCommentCount(ctx, 1, 4); err != nil {
+		t.Fatalf("SetCommentCount: %v", err)
+	}
+	blog, err := blogs.IncrementCommentCount(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("IncrementCommentCount: %v", err)
+	}
+	if blog.CommentCount != 5 {
+		t.Errorf("CommentCount = %d, want 5", blog.CommentCount)
+	}
+
+	// Once tracked, a late backfill mustn't overwrite the live count.
+	if err := blogs.SetCommentCount(ctx, 1, 0); err != nil {
+		t.Fatalf("SetCommentCount: %v", err)
+	}
+	found, err := blogs.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if found.CommentCount != 5 {
+		t.Errorf("CommentCount after a second backfill = %d, want 5", found.CommentCount)
+	}
+}
+
+func TestSetSagaStateTransitions(t *testing.T) {
+	client, cfg := testMongo(t)
+	ctx := context.Background()
+	comments := NewCommentRepository(client, cfg, NewCounterRepository(client, cfg, nil), nil)
+
+	if _, err := comments.Collection.InsertOne(ctx, model.Comment{Id: 1, BlogId: 1, Text: "new", SagaState: model.CommentPending}); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	// A comment stored before the saga has no state at all.
+	if _, err := comments.Collection.InsertOne(ctx, bson.M{"id": 2, "blogid": 1, "text": "old"}); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+
+	confirm := []model.CommentSagaState{model.CommentPending}
+	compensate := []model.CommentSagaState{"", model.CommentPending, model.CommentConfirmed}
+	steps := []struct {
+		name        string
+		id          int64
+		from        []model.CommentSagaState
+		state       model.CommentSagaState
+		wantChanged bool
+		wantBefore  model.CommentSagaState
+	}{
+		{"confirm pending", 1, confirm, model.CommentConfirmed, true, model.CommentPending},
+		{"confirm again", 1, confirm, model.CommentConfirmed, false, model.CommentConfirmed},
+		{"compensate confirmed", 1, compensate, model.CommentCompensated, true, model.CommentConfirmed},
+		{"confirm compensated", 1, confirm, model.CommentConfirmed, false, model.CommentCompensated},
+		{"compensate again", 1, compensate, model.CommentCompensated, false, model.CommentCompensated},
+		{"compensate without a state", 2, compensate, model.CommentCompensated, true, ""},
+	}
+	for _, step := range steps {
+		before, changed, err := comments.SetSagaState(ctx, step.id, step.from, step.state)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if changed != step.wantChanged || before.SagaState != step.wantBefore {
+			t.Errorf("%s: changed %v from %q, want %v from %q", step.name, changed, before.SagaState, step.wantChanged, step.wantBefore)
+		}
+	}
+
+	if _, _, err := comments.SetSagaState(ctx, 99, confirm, model.CommentConfirmed); model.KindOf(err) != model.KindNotFound {
+		t.Errorf("SetSagaState of a missing comment = %v, want not found", err)
+	}
+}
+
+func TestCountRepliesOnlyCountsConfirmedReplies(t *testing.T) {
+	client, cfg := testMongo(t)
+	ctx := context.Background()
+	comments := NewCommentRepository(client, cfg, NewCounterRepository(client, cfg, nil), nil)
+
+	parent := func(id int) *int { return &id }
+	stored := []model.Comment{
+		{Id: 1, BlogId: 1, Text: "parent"},
+		{Id: 2, BlogId: 1, ParentId: parent(1), Depth: 1, Text: "pending", SagaState: model.CommentPending},
+		{Id: 3, BlogId: 1, ParentId: parent(1), Depth: 1, Text: "compensated", SagaState: model.CommentCompensated},
+		{Id: 4, BlogId: 1, Text: "other parent"},
+		{Id: 5, BlogId: 1, ParentId: parent(4), Depth: 1, Text: "confirmed", SagaState: model.CommentConfirmed},
+		{Id: 6, BlogId: 1, ParentId: parent(4), Depth: 1, Text: model.DeletedCommentText, SagaState: model.CommentConfirmed, Deleted: true},
+	}
+	for _, comment := range stored {
+		if _, err := comments.Collection.InsertOne(ctx, comment); err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+	}
+
+	// Replies that were rolled back or never confirmed don't need a
+	// tombstone; confirmed ones, tombstones included, do.
+	for id, want := range map[int64]int64{1: 0, 4: 2} {
+		count, err := comments.CountReplies(ctx, id)
+		if err != nil {
+			t.Fatalf("CountReplies: %v", err)
+		}
+		if count != want {
+			t.Errorf("CountReplies(%d) = %d, want %d", id, count, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := withTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("deadline = %v, %v; want one within a minute", deadline, ok)
+	}
+
+	// A sooner caller deadline wins.
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Second)
+	defer cancelParent()
+	want, _ := parent.Deadline()
+	ctx, cancel = withTimeout(parent, time.Minute)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(want) {
+		t.Errorf("deadline = %v, want the caller's %v", deadline, want)
+	}
+
+	// Without a timeout there's no deadline, but cancel still works.
+	ctx, cancel = withTimeout(context.Background(), 0)
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("got a deadline without a timeout")
+	}
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("cancel didn't cancel")
+	}
+}
+
+func TestDetachKeepsOnlyTheSpan(t *testing.T) {
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	// Starting a session doesn't talk to the server, so none is needed.
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+	session, err := client.StartSession()
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	defer session.EndSession(context.Background())
+
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+	ctx = mongo.NewSessionContext(ctx, session)
+	if mongo.SessionFromContext(ctx) == nil {
+		t.Fatal("the session isn't in the context")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	detached := detach(ctx)
+	if detached.Err() != nil {
+		t.Error("detached context was cancelled with its parent")
+	}
+	if mongo.SessionFromContext(detached) != nil {
+		t.Error("detached context kept the Mongo session")
+	}
+	if got := trace.SpanContextFromContext(detached); !got.Equal(spanContext) {
+		t.Errorf("span context = %v, want %v", got, spanContext)
+	}
+}
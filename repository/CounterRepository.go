@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"BlogApplication/config"
+	"BlogApplication/logging"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Counter names, one per collection that allocates sequential ids.
+const (
+	BlogCounter     = "blogs"
+	CommentCounter  = "comments"
+	ReportCounter   = "reports"
+	RevisionCounter = "blog_revisions"
+	VoteCounter     = "votes"
+	OutboxCounter   = "outbox"
+)
+
+type counter struct {
+	Name string `bson:"_id"`
+	Seq  int    `bson:"seq"`
+}
+
+type CounterRepository struct {
+	Collection *mongo.Collection
+	Timeout    time.Duration
+	Logger     *slog.Logger
+}
+
+func NewCounterRepository(client *mongo.Client, cfg config.MongoConfig, logger *slog.Logger) *CounterRepository {
+	database := client.Database(cfg.Database)
+	collection := database.Collection("counters")
+	return &CounterRepository{
+		Collection: collection,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
+	}
+}
+
+// NextId atomically increments the named counter and returns the new value,
+// creating the counter on first use. The increment never joins the caller's
+// transaction: every insert bumps the same counter document, so concurrent
+// transactions would keep aborting each other with write conflicts. An id
+// lost to a rolled back insert just leaves a gap.
+func (repository *CounterRepository) NextId(ctx context.Context, name string) (int, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "NextId")
+	defer span.End()
+	ctx, cancel := withTimeout(detach(ctx), repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(attribute.String("counter.name", name))
+
+	filter := bson.M{"_id": name}
+	update := bson.M{"$inc": bson.M{"seq": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var c counter
+	err := repository.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&c)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "NextId failed", "error", err)
+		span.SetStatus(codes.Error, "NextId failed")
+		return 0, err
+	}
+
+	span.SetStatus(codes.Ok, "NextId successful")
+	return c.Seq, nil
+}
+
+// Bootstrap seeds the named counter with the highest id already stored in
+// collection. It is idempotent and meant to run on every startup: $max only
+// ever raises the counter, so a counter that is already ahead is left alone,
+// while one that fell behind, because documents were restored from a backup
+// or written before counters existed, is moved past the highest stored id.
+// Allocation stays correct even if two instances bootstrap at once.
+func (repository *CounterRepository) Bootstrap(ctx context.Context, name string, collection *mongo.Collection) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Bootstrap")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(attribute.String("counter.name", name))
+
+	var last struct {
+		Id int `bson:"id"`
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "id", Value: -1}}).SetProjection(bson.M{"id": 1})
+	err := collection.FindOne(ctx, bson.M{}, opts).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		repository.Logger.DebugContext(ctx, "Bootstrap failed", "error", err)
+		span.SetStatus(codes.Error, "Bootstrap failed")
+		return err
+	}
+
+	filter := bson.M{"_id": name}
+	update := bson.M{"$max": bson.M{"seq": last.Id}}
+	_, err = repository.Collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Bootstrap failed", "error", err)
+		span.SetStatus(codes.Error, "Bootstrap failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "Bootstrap successful")
+	return nil
+}
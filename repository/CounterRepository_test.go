@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNextIdIsUniqueUnderConcurrency(t *testing.T) {
+	client, cfg := testMongo(t)
+	counters := NewCounterRepository(client, cfg, nil)
+
+	const creates = 1000
+	ids := make(chan int, creates)
+	errs := make(chan error, creates)
+	var wg sync.WaitGroup
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := counters.NextId(context.Background(), BlogCounter)
+			if err != nil {
+				errs <- err
+				return
+			}
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("NextId: %v", err)
+	}
+	seen := make(map[int]bool, creates)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("id %d was allocated twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != creates {
+		t.Fatalf("got %d ids, want %d", len(seen), creates)
+	}
+	for id := 1; id <= creates; id++ {
+		if !seen[id] {
+			t.Fatalf("id %d was skipped", id)
+		}
+	}
+}
+
+func TestBootstrapOnlyRaisesTheCounter(t *testing.T) {
+	client, cfg := testMongo(t)
+	ctx := context.Background()
+	counters := NewCounterRepository(client, cfg, nil)
+	blogs := client.Database(cfg.Database).Collection("blogs")
+
+	if _, err := blogs.InsertMany(ctx, []interface{}{bson.M{"id": 3}, bson.M{"id": 7}}); err != nil {
+		t.Fatalf("seeding blogs: %v", err)
+	}
+	if err := counters.Bootstrap(ctx, BlogCounter, blogs); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	if id, err := counters.NextId(ctx, BlogCounter); err != nil || id != 8 {
+		t.Fatalf("NextId after Bootstrap = %d, %v; want 8", id, err)
+	}
+
+	// Running it again, as every startup does, mustn't move the counter back.
+	if err := counters.Bootstrap(ctx, BlogCounter, blogs); err != nil {
+		t.Fatalf("second Bootstrap: %v", err)
+	}
+	if id, err := counters.NextId(ctx, BlogCounter); err != nil || id != 9 {
+		t.Fatalf("NextId after second Bootstrap = %d, %v; want 9", id, err)
+	}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"BlogApplication/model"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// notFoundOr reports a missing document as a model.NotFound error described
+// by format and args, and passes any other error through unchanged.
+func notFoundOr(err error, format string, args ...interface{}) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.NotFound(format, args...)
+	}
+	return err
+}
+
+// conflictOr reports a unique index violation as a model.Conflict error
+// saying that the document described by format and args already exists, and
+// passes any other error through unchanged.
+func conflictOr(err error, format string, args ...interface{}) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return model.Conflict(format+" already exists", args...)
+	}
+	return err
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"BlogApplication/config"
+	"BlogApplication/logging"
+	"BlogApplication/model"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// SentOutboxRetention is how long sent messages are kept before Mongo
+// expires them.
+const SentOutboxRetention = 7 * 24 * time.Hour
+
+type OutboxRepository struct {
+	Collection *mongo.Collection
+	Counters   *CounterRepository
+	Timeout    time.Duration
+	Logger     *slog.Logger
+}
+
+func NewOutboxRepository(client *mongo.Client, cfg config.MongoConfig, counters *CounterRepository, logger *slog.Logger) *OutboxRepository {
+	database := client.Database(cfg.Database)
+	collection := database.Collection("outbox")
+	return &OutboxRepository{
+		Collection: collection,
+		Counters:   counters,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
+	}
+}
+
+// EnsureIndexes indexes the pending backlog in publishing order and expires
+// sent messages. Pending messages have no sentat date, so they never expire.
+func (repository *OutboxRepository) EnsureIndexes(ctx context.Context) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "EnsureIndexes")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "sentat", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetName("pending"),
+		},
+		{
+			Keys:    bson.D{{Key: "sentat", Value: 1}},
+			Options: options.Index().SetName("sent_expiry").SetExpireAfterSeconds(int32(SentOutboxRetention.Seconds())),
+		},
+	}
+	_, err := repository.Collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "EnsureIndexes failed", "error", err)
+		span.SetStatus(codes.Error, "EnsureIndexes failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "EnsureIndexes successful")
+	return nil
+}
+
+// Create adds a message to the backlog, inside the caller's transaction if
+// ctx carries one.
+func (repository *OutboxRepository) Create(ctx context.Context, message *model.OutboxMessage) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Create")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(attribute.String("messaging.destination.name", message.Subject))
+
+	id, err := repository.Counters.NextId(ctx, OutboxCounter)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
+		span.SetStatus(codes.Error, "Create failed")
+		return err
+	}
+	message.Id = id
+
+	_, err = repository.Collection.InsertOne(ctx, message)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
+		span.SetStatus(codes.Error, "Create failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "Create successful")
+	return nil
+}
+
+// FindPending returns up to limit unsent messages, oldest first.
+func (repository *OutboxRepository) FindPending(ctx context.Context, limit int64) ([]model.OutboxMessage, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindPending")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(attribute.Int64("outbox.batch_size", limit))
+
+	opts := options.Find().SetSort(bson.D{{Key: "id", Value: 1}}).SetLimit(limit)
+	cur, err := repository.Collection.Find(ctx, bson.M{"sentat": nil}, opts)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "FindPending failed", "error", err)
+		span.SetStatus(codes.Error, "FindPending failed")
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	messages := make([]model.OutboxMessage, 0)
+	err = cur.All(ctx, &messages)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "FindPending failed", "error", err)
+		span.SetStatus(codes.Error, "FindPending failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "FindPending successful")
+	return messages, nil
+}
+
+func (repository *OutboxRepository) MarkSent(ctx context.Context, ids []int, sentAt time.Time) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "MarkSent")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(attribute.Int("request.count", len(ids)))
+
+	filter := bson.M{"id": bson.M{"$in": ids}}
+	update := bson.M{"$set": bson.M{"sentat": sentAt}, "$inc": bson.M{"attempts": 1}, "$unset": bson.M{"lasterror": ""}}
+	_, err := repository.Collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "MarkSent failed", "error", err)
+		span.SetStatus(codes.Error, "MarkSent failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "MarkSent successful")
+	return nil
+}
+
+// MarkFailed counts a failed attempt to publish the messages and remembers
+// why, leaving them in the backlog.
+func (repository *OutboxRepository) MarkFailed(ctx context.Context, ids []int, reason string) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "MarkFailed")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(attribute.Int("request.count", len(ids)))
+
+	filter := bson.M{"id": bson.M{"$in": ids}}
+	update := bson.M{"$set": bson.M{"lasterror": reason}, "$inc": bson.M{"attempts": 1}}
+	_, err := repository.Collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "MarkFailed failed", "error", err)
+		span.SetStatus(codes.Error, "MarkFailed failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "MarkFailed successful")
+	return nil
+}
+
+// CountPending returns the size of the unsent backlog.
+func (repository *OutboxRepository) CountPending(ctx context.Context) (int64, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "CountPending")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	count, err := repository.Collection.CountDocuments(ctx, bson.M{"sentat": nil})
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "CountPending failed", "error", err)
+		span.SetStatus(codes.Error, "CountPending failed")
+		return 0, err
+	}
+
+	span.SetStatus(codes.Ok, "CountPending successful")
+	return count, nil
+}
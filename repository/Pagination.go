@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"BlogApplication/model"
+	"BlogApplication/useCases"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findPage returns the page of documents matching filter that follows the
+// request's cursor, newest first by (dateField, id). An empty dateField pages
+// by id alone. The total counts every match, regardless of the cursor.
+func findPage[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, dateField string, page useCases.PageRequest, cursorOf func(T) useCases.Cursor) (*useCases.PagedResult[T], error) {
+	cursor, err := pageCursor(page)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := filter
+	sort := bson.D{{Key: "id", Value: -1}}
+	if dateField != "" {
+		sort = bson.D{{Key: dateField, Value: -1}, {Key: "id", Value: -1}}
+	}
+	if cursor != nil {
+		query = bson.M{"$and": bson.A{filter, after(cursor, dateField)}}
+	}
+
+	// One extra document tells whether another page follows.
+	limit := page.Limit()
+	opts := options.Find().SetSort(sort).SetLimit(limit + 1)
+	cur, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var items = make([]T, 0)
+	if err := cur.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	result := &useCases.PagedResult[T]{Items: items, Total: total}
+	if int64(len(items)) > limit {
+		result.Items = items[:limit]
+		result.NextPageToken = cursorOf(items[limit-1]).Encode()
+	}
+	return result, nil
+}
+
+// pageCursor decodes the request's page token, reporting a malformed one as
+// an invalid page_token field rather than a storage failure.
+func pageCursor(page useCases.PageRequest) (*useCases.Cursor, error) {
+	cursor, err := page.Cursor()
+	if errors.Is(err, useCases.ErrInvalidPageToken) {
+		return nil, model.InvalidField("page_token", "invalid page token")
+	}
+	return cursor, err
+}
+
+func after(cursor *useCases.Cursor, dateField string) bson.M {
+	if dateField == "" {
+		return bson.M{"id": bson.M{"$lt": cursor.Id}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{dateField: bson.M{"$lt": cursor.Date}},
+		bson.M{dateField: cursor.Date, "id": bson.M{"$lt": cursor.Id}},
+	}}
+}
+
+func blogCursor(blog model.Blog) useCases.Cursor {
+	return useCases.Cursor{Date: blog.Date, Id: blog.Id}
+}
+
+func commentCursor(comment model.Comment) useCases.Cursor {
+	return useCases.Cursor{Date: comment.CreatedAt, Id: comment.Id}
+}
+
+func reportCursor(report model.Report) useCases.Cursor {
+	return useCases.Cursor{Id: report.Id}
+}
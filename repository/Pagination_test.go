@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"BlogApplication/model"
+	"BlogApplication/useCases"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPageCursorRejectsMalformedTokens(t *testing.T) {
+	for _, token := range []string{"%%%", "bm90IGpzb24", "e30"} {
+		_, err := pageCursor(useCases.PageRequest{Token: token})
+		var domainError *model.DomainError
+		if !errors.As(err, &domainError) || domainError.Kind != model.KindInvalidArgument {
+			t.Errorf("token %q: got %v, want an invalid argument", token, err)
+			continue
+		}
+		if len(domainError.Violations) != 1 || domainError.Violations[0].Field != "page_token" {
+			t.Errorf("token %q: violations = %+v, want one on page_token", token, domainError.Violations)
+		}
+	}
+}
+
+func TestPageCursorDecodesValidTokens(t *testing.T) {
+	cursor, err := pageCursor(useCases.PageRequest{})
+	if err != nil || cursor != nil {
+		t.Fatalf("empty token = %v, %v; want the first page", cursor, err)
+	}
+
+	want := useCases.Cursor{Date: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Id: 42}
+	cursor, err = pageCursor(useCases.PageRequest{Token: want.Encode()})
+	if err != nil {
+		t.Fatalf("pageCursor: %v", err)
+	}
+	if !cursor.Date.Equal(want.Date) || cursor.Id != want.Id {
+		t.Errorf("cursor = %+v, want %+v", *cursor, want)
+	}
+}
@@ -1,81 +1,80 @@
 package repository
 
 import (
+	"BlogApplication/config"
+	"BlogApplication/logging"
 	"BlogApplication/model"
+	"BlogApplication/telemetry"
+	"BlogApplication/useCases"
 	"context"
-	"encoding/json"
+	"log/slog"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
 type ReportRepository struct {
 	Collection *mongo.Collection
+	Counters   *CounterRepository
+	Timeout    time.Duration
+	Logger     *slog.Logger
 }
 
-func NewReportRepository(client *mongo.Client) *ReportRepository {
-	database := client.Database("soa")
+func NewReportRepository(client *mongo.Client, cfg config.MongoConfig, counters *CounterRepository, logger *slog.Logger) *ReportRepository {
+	database := client.Database(cfg.Database)
 	collection := database.Collection("reports")
 	return &ReportRepository{
 		Collection: collection,
+		Counters:   counters,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
 	}
 }
 
-func (repository *ReportRepository) FindAllByBlog(ctx context.Context, blogID int64) ([]model.Report, error) {
+func (repository *ReportRepository) FindAllByBlogPage(ctx context.Context, blogID int64, page useCases.PageRequest) (*useCases.PagedResult[model.Report], error) {
 	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "FindAllByBlog")
+	ctx, span := tracer.Start(ctx, "FindAllByBlogPage")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(blogID)+" }"))
+	span.SetAttributes(telemetry.BlogId(blogID))
 
-	var reports = make([]model.Report, 0)
-	filter := bson.M{"blogid": blogID}
-	cur, err := repository.Collection.Find(context.Background(), filter)
+	result, err := findPage(ctx, repository.Collection, bson.M{"blogid": blogID}, "", page, reportCursor)
 	if err != nil {
-		span.SetStatus(codes.Error, "FindAllByBlog failed")
-		return nil, err
-	}
-	defer cur.Close(context.Background())
-	for cur.Next(context.Background()) {
-		var report model.Report
-		err := cur.Decode(&report)
-		if err != nil {
-			span.SetStatus(codes.Error, "FindAllByBlog failed")
-			return nil, err
-		}
-		reports = append(reports, report)
-	}
-	if err := cur.Err(); err != nil {
-		span.SetStatus(codes.Error, "FindAllByBlog failed")
+		repository.Logger.DebugContext(ctx, "FindAllByBlogPage failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllByBlogPage failed")
 		return nil, err
 	}
 
-	span.SetStatus(codes.Ok, "FindAllByBlog successful")
-	return reports, nil
+	span.SetStatus(codes.Ok, "FindAllByBlogPage successful")
+	return result, nil
 }
 
 func (repository *ReportRepository) Create(ctx context.Context, report *model.Report) error {
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "Create")
 	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
-	reqData, jsonError := json.Marshal(report)
-	if jsonError != nil {
-		span.RecordError(jsonError)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return jsonError
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(int64(report.BlogId)), telemetry.UserId(int64(report.UserId)))
 
-	report.Id = repository.NextId(ctx)
-	_, err := repository.Collection.InsertOne(context.Background(), report)
+	id, err := repository.NextId(ctx)
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "Create failed", "error", err)
 		span.SetStatus(codes.Error, "Create failed")
 		return err
 	}
+	report.Id = id
+	_, err = repository.Collection.InsertOne(ctx, report)
+	if err != nil {
+		span.SetStatus(codes.Error, "Create failed")
+		return conflictOr(err, "report with id %d", report.Id)
+	}
 
 	span.SetStatus(codes.Ok, "Create successful")
 	return nil
@@ -85,26 +84,29 @@ func (repository *ReportRepository) GetAll(ctx context.Context) ([]model.Report,
 	tracer := otel.Tracer("repository")
 	ctx, span := tracer.Start(ctx, "GetAll")
 	defer span.End()
-
-	span.SetAttributes(attribute.String("request.data", "{}"))
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
 
 	var reports []model.Report
-	cur, err := repository.Collection.Find(context.Background(), bson.M{})
+	cur, err := repository.Collection.Find(ctx, bson.M{})
 	if err != nil {
+		repository.Logger.DebugContext(ctx, "GetAll failed", "error", err)
 		span.SetStatus(codes.Error, "GetAll failed")
 		return nil, err
 	}
-	defer cur.Close(context.Background())
-	for cur.Next(context.Background()) {
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
 		var report model.Report
 		err := cur.Decode(&report)
 		if err != nil {
+			repository.Logger.DebugContext(ctx, "GetAll failed", "error", err)
 			span.SetStatus(codes.Error, "GetAll failed")
 			return nil, err
 		}
 		reports = append(reports, report)
 	}
 	if err := cur.Err(); err != nil {
+		repository.Logger.DebugContext(ctx, "GetAll failed", "error", err)
 		span.SetStatus(codes.Error, "GetAll failed")
 		return nil, err
 	}
@@ -113,20 +115,6 @@ func (repository *ReportRepository) GetAll(ctx context.Context) ([]model.Report,
 	return reports, nil
 }
 
-func (repository *ReportRepository) NextId(ctx context.Context) int {
-	tracer := otel.Tracer("repository")
-	ctx, span := tracer.Start(ctx, "NextId")
-	defer span.End()
-
-	reports, _ := repository.GetAll(ctx)
-
-	maxId := 0
-	for _, report := range reports {
-		if report.Id > maxId {
-			maxId = report.Id
-		}
-	}
-
-	span.SetStatus(codes.Ok, "NextId successful")
-	return maxId + 1
+func (repository *ReportRepository) NextId(ctx context.Context) (int, error) {
+	return repository.Counters.NextId(ctx, ReportCounter)
 }
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"BlogApplication/config"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testMongo connects to the MongoDB named by MONGO_TEST_URI and returns a
+// client and a config for a database of its own, dropped when the test ends.
+// Tests that need it are skipped when no test instance is configured.
+func testMongo(t *testing.T) (*mongo.Client, config.MongoConfig) {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI isn't set; run make test-mongo to test against MongoDB")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connecting to test MongoDB: %v", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatalf("pinging test MongoDB: %v", err)
+	}
+
+	cfg := config.MongoConfig{
+		URI:              uri,
+		Database:         fmt.Sprintf("blog_test_%d", time.Now().UnixNano()),
+		OperationTimeout: 10 * time.Second,
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = client.Database(cfg.Database).Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+	return client, cfg
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"BlogApplication/logging"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TransactionRunner runs groups of repository calls in one Mongo transaction.
+// Repository methods join the transaction through the context they're given.
+type TransactionRunner struct {
+	Client *mongo.Client
+	// Supported is false on a standalone server, which can't run
+	// transactions. The calls then run one after another without one, which
+	// is only allowed when the deployment opts in.
+	Supported bool
+	Logger    *slog.Logger
+}
+
+// NewTransactionRunner asks the server whether it's part of a replica set or
+// sharded cluster, the deployments that support transactions. On a
+// standalone server it fails unless allowStandalone is set, since writes and
+// the events they enqueue would then no longer be atomic.
+func NewTransactionRunner(ctx context.Context, client *mongo.Client, allowStandalone bool, logger *slog.Logger) (*TransactionRunner, error) {
+	logger = logging.OrDefault(logger)
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return nil, fmt.Errorf("error checking MongoDB deployment: %w", err)
+	}
+	supported := hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !supported {
+		if !allowStandalone {
+			return nil, errors.New("MongoDB deployment doesn't support transactions; run it as a replica set or set mongo.allow_standalone")
+		}
+		logger.WarnContext(ctx, "MongoDB deployment doesn't support transactions, writes and their events won't be atomic")
+	}
+	return &TransactionRunner{Client: client, Supported: supported, Logger: logger}, nil
+}
+
+// WithTransaction runs fn in a transaction and commits it if fn succeeds. fn
+// may be retried on transient errors, so it must not have side effects
+// outside the database.
+func (runner *TransactionRunner) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "WithTransaction")
+	defer span.End()
+
+	if !runner.Supported {
+		err := fn(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, "WithTransaction failed")
+			return err
+		}
+		span.SetStatus(codes.Ok, "WithTransaction successful")
+		return nil
+	}
+
+	session, err := runner.Client.StartSession()
+	if err != nil {
+		runner.Logger.DebugContext(ctx, "WithTransaction failed", "error", err)
+		span.SetStatus(codes.Error, "WithTransaction failed")
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionContext)
+	})
+	if err != nil {
+		runner.Logger.DebugContext(ctx, "WithTransaction failed", "error", err)
+		span.SetStatus(codes.Error, "WithTransaction failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "WithTransaction successful")
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNewTransactionRunnerRefusesStandaloneUnlessAllowed(t *testing.T) {
+	client, _ := testMongo(t)
+	ctx := context.Background()
+
+	strict, err := NewTransactionRunner(ctx, client, false, nil)
+	if err == nil && !strict.Supported {
+		t.Fatal("runner without transactions was created without allowStandalone")
+	}
+	lenient, err := NewTransactionRunner(ctx, client, true, nil)
+	if err != nil {
+		t.Fatalf("NewTransactionRunner with allowStandalone: %v", err)
+	}
+	if strict != nil && strict.Supported != lenient.Supported {
+		t.Errorf("transaction support depends on allowStandalone")
+	}
+}
+
+func TestWithTransactionRollsBackOnError(t *testing.T) {
+	client, cfg := testMongo(t)
+	ctx := context.Background()
+	runner, err := NewTransactionRunner(ctx, client, false, nil)
+	if err != nil {
+		t.Skipf("test MongoDB doesn't support transactions: %v", err)
+	}
+	collection := client.Database(cfg.Database).Collection("transactions")
+	// Collections can't be created inside a transaction on older servers.
+	if err := client.Database(cfg.Database).CreateCollection(ctx, "transactions"); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+
+	failure := errors.New("abort")
+	err = runner.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := collection.InsertOne(ctx, bson.M{"id": 1}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("WithTransaction = %v, want %v", err, failure)
+	}
+	count, err := collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatalf("CountDocuments: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("%d documents survived the rolled back transaction", count)
+	}
+}
@@ -1,53 +1,227 @@
 package repository
 
 import (
+	"BlogApplication/config"
+	"BlogApplication/logging"
 	"BlogApplication/model"
+	"BlogApplication/telemetry"
+	"context"
+	"log/slog"
+	"time"
 
-	"gorm.io/gorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// VoteRepository stores one vote per (blog, user) pair, enforced by a unique
+// index.
 type VoteRepository struct {
-	DatabaseConnection *gorm.DB
+	Collection *mongo.Collection
+	Counters   *CounterRepository
+	Timeout    time.Duration
+	Logger     *slog.Logger
 }
 
-func (repo *VoteRepository) FindById(id int) (model.Vote, error) {
-	vote := model.Vote{}
-	dbResult := repo.DatabaseConnection.First(&vote, id)
-	if dbResult.Error != nil {
-		return vote, dbResult.Error
+func NewVoteRepository(client *mongo.Client, cfg config.MongoConfig, counters *CounterRepository, logger *slog.Logger) *VoteRepository {
+	database := client.Database(cfg.Database)
+	collection := database.Collection("votes")
+	return &VoteRepository{
+		Collection: collection,
+		Counters:   counters,
+		Timeout:    cfg.OperationTimeout,
+		Logger:     logging.OrDefault(logger),
 	}
-	return vote, nil
 }
 
-func (repo *VoteRepository) Create(vote *model.Vote) error {
-	dbResult := repo.DatabaseConnection.Create(vote)
-	if dbResult.Error != nil {
-		return dbResult.Error
+func (repository *VoteRepository) EnsureIndexes(ctx context.Context) error {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "EnsureIndexes")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "blogid", Value: 1}, {Key: "userid", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("blog_user_unique"),
+	}
+	_, err := repository.Collection.Indexes().CreateOne(ctx, index)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "EnsureIndexes failed", "error", err)
+		span.SetStatus(codes.Error, "EnsureIndexes failed")
+		return err
 	}
+
+	span.SetStatus(codes.Ok, "EnsureIndexes successful")
 	return nil
 }
 
-func (repo *VoteRepository) Update(vote *model.Vote) error {
-	dbResult := repo.DatabaseConnection.Save(vote)
-	if dbResult.Error != nil {
-		return dbResult.Error
+// Upsert stores the user's vote on a blog and returns the vote it replaced,
+// or nil if the user hadn't voted on the blog yet. An id is only allocated
+// for a first vote; changing a vote keeps its id.
+func (repository *VoteRepository) Upsert(ctx context.Context, vote *model.Vote) (*model.Vote, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Upsert")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(vote.BlogId), telemetry.UserId(vote.UserId))
+
+	filter := bson.M{"blogid": vote.BlogId, "userid": vote.UserId}
+	set := bson.M{"$set": bson.M{"votetype": vote.VoteType}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var previous model.Vote
+	err := repository.Collection.FindOneAndUpdate(ctx, filter, set, opts).Decode(&previous)
+	if err == nil {
+		vote.Id = previous.Id
+		span.SetStatus(codes.Ok, "Upsert successful")
+		return &previous, nil
 	}
-	return nil
+	if err != mongo.ErrNoDocuments {
+		repository.Logger.DebugContext(ctx, "Upsert failed", "error", err)
+		span.SetStatus(codes.Error, "Upsert failed")
+		return nil, err
+	}
+
+	id, err := repository.Counters.NextId(ctx, VoteCounter)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Upsert failed", "error", err)
+		span.SetStatus(codes.Error, "Upsert failed")
+		return nil, err
+	}
+
+	// Still an upsert, so a vote the user cast concurrently since the check
+	// above is updated instead of tripping the unique index.
+	update := bson.M{
+		"$set":         bson.M{"votetype": vote.VoteType},
+		"$setOnInsert": bson.M{"id": id},
+	}
+	err = repository.Collection.FindOneAndUpdate(ctx, filter, update, opts.SetUpsert(true)).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		vote.Id = id
+		span.SetStatus(codes.Ok, "Upsert successful")
+		return nil, nil
+	}
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Upsert failed", "error", err)
+		span.SetStatus(codes.Error, "Upsert failed")
+		return nil, err
+	}
+
+	vote.Id = previous.Id
+	span.SetStatus(codes.Ok, "Upsert successful")
+	return &previous, nil
 }
 
-func (repo *VoteRepository) Delete(id int) error {
-	dbResult := repo.DatabaseConnection.Delete(&model.Vote{}, id)
-	if dbResult.Error != nil {
-		return dbResult.Error
+// Insert stores a vote unless the user already voted on the blog, and
+// reports whether it did.
+func (repository *VoteRepository) Insert(ctx context.Context, vote *model.Vote) (bool, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Insert")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(vote.BlogId), telemetry.UserId(vote.UserId))
+
+	id, err := repository.Counters.NextId(ctx, VoteCounter)
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Insert failed", "error", err)
+		span.SetStatus(codes.Error, "Insert failed")
+		return false, err
 	}
-	return nil
+	vote.Id = id
+	_, err = repository.Collection.InsertOne(ctx, vote)
+	if mongo.IsDuplicateKeyError(err) {
+		span.SetStatus(codes.Ok, "Insert skipped")
+		return false, nil
+	}
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Insert failed", "error", err)
+		span.SetStatus(codes.Error, "Insert failed")
+		return false, err
+	}
+
+	span.SetStatus(codes.Ok, "Insert successful")
+	return true, nil
 }
 
-func (repo *VoteRepository) GetAll() ([]model.Vote, error) {
-	var votes []model.Vote
-	dbResult := repo.DatabaseConnection.Find(&votes)
-	if dbResult.Error != nil {
-		return nil, dbResult.Error
+func (repository *VoteRepository) FindAllByBlog(ctx context.Context, blogID int64) ([]model.Vote, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "FindAllByBlog")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	var votes = make([]model.Vote, 0)
+	cur, err := repository.Collection.Find(ctx, bson.M{"blogid": blogID})
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "FindAllByBlog failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllByBlog failed")
+		return nil, err
+	}
+	if err := cur.All(ctx, &votes); err != nil {
+		repository.Logger.DebugContext(ctx, "FindAllByBlog failed", "error", err)
+		span.SetStatus(codes.Error, "FindAllByBlog failed")
+		return nil, err
 	}
+
+	span.SetStatus(codes.Ok, "FindAllByBlog successful")
 	return votes, nil
 }
+
+// DeleteAllByBlog removes every vote cast on a blog and returns how many
+// there were.
+func (repository *VoteRepository) DeleteAllByBlog(ctx context.Context, blogID int64) (int64, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "DeleteAllByBlog")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	result, err := repository.Collection.DeleteMany(ctx, bson.M{"blogid": blogID})
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "DeleteAllByBlog failed", "error", err)
+		span.SetStatus(codes.Error, "DeleteAllByBlog failed")
+		return 0, err
+	}
+
+	span.SetStatus(codes.Ok, "DeleteAllByBlog successful")
+	return result.DeletedCount, nil
+}
+
+// Delete removes the user's vote on a blog and returns it, or nil if the user
+// hadn't voted on the blog.
+func (repository *VoteRepository) Delete(ctx context.Context, blogID int64, userID int64) (*model.Vote, error) {
+	tracer := otel.Tracer("repository")
+	ctx, span := tracer.Start(ctx, "Delete")
+	defer span.End()
+	ctx, cancel := withTimeout(ctx, repository.Timeout)
+	defer cancel()
+
+	span.SetAttributes(telemetry.BlogId(blogID), telemetry.UserId(userID))
+
+	var vote model.Vote
+	filter := bson.M{"blogid": blogID, "userid": userID}
+	err := repository.Collection.FindOneAndDelete(ctx, filter).Decode(&vote)
+	if err == mongo.ErrNoDocuments {
+		span.SetStatus(codes.Ok, "Delete successful")
+		return nil, nil
+	}
+	if err != nil {
+		repository.Logger.DebugContext(ctx, "Delete failed", "error", err)
+		span.SetStatus(codes.Error, "Delete failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "Delete successful")
+	return &vote, nil
+}
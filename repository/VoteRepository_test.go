@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"BlogApplication/model"
+	"context"
+	"testing"
+)
+
+func testVoteRepository(t *testing.T) (*VoteRepository, *CounterRepository) {
+	t.Helper()
+	client, cfg := testMongo(t)
+	counters := NewCounterRepository(client, cfg, nil)
+	votes := NewVoteRepository(client, cfg, counters, nil)
+	if err := votes.EnsureIndexes(context.Background()); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+	return votes, counters
+}
+
+func TestUpsertKeepsTheIdOfAChangedVote(t *testing.T) {
+	votes, counters := testVoteRepository(t)
+	ctx := context.Background()
+
+	first := &model.Vote{BlogId: 1, UserId: 2, VoteType: model.Upvote}
+	previous, err := votes.Upsert(ctx, first)
+	if err != nil || previous != nil {
+		t.Fatalf("first Upsert = %v, %v; want no previous vote", previous, err)
+	}
+
+	changed := &model.Vote{BlogId: 1, UserId: 2, VoteType: model.Downvote}
+	previous, err = votes.Upsert(ctx, changed)
+	if err != nil {
+		t.Fatalf("second Upsert: %v", err)
+	}
+	if previous == nil || previous.VoteType != model.Upvote {
+		t.Fatalf("previous = %+v, want the upvote", previous)
+	}
+	if changed.Id != first.Id {
+		t.Errorf("changed vote got id %d, want %d", changed.Id, first.Id)
+	}
+
+	// Changing the vote mustn't have used up an id.
+	next, err := counters.NextId(ctx, VoteCounter)
+	if err != nil {
+		t.Fatalf("NextId: %v", err)
+	}
+	if next != first.Id+1 {
+		t.Errorf("next vote id = %d, want %d", next, first.Id+1)
+	}
+}
+
+func TestDeleteAllByBlogLeavesOtherBlogsAlone(t *testing.T) {
+	votes, _ := testVoteRepository(t)
+	ctx := context.Background()
+
+	for _, vote := range []*model.Vote{
+		{BlogId: 1, UserId: 1, VoteType: model.Upvote},
+		{BlogId: 1, UserId: 2, VoteType: model.Downvote},
+		{BlogId: 2, UserId: 1, VoteType: model.Upvote},
+	} {
+		if _, err := votes.Upsert(ctx, vote); err != nil {
+			t.Fatalf("Upsert: %v", err)
+		}
+	}
+
+	deleted, err := votes.DeleteAllByBlog(ctx, 1)
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteAllByBlog = %d, %v; want 2", deleted, err)
+	}
+	if remaining, err := votes.FindAllByBlog(ctx, 1); err != nil || len(remaining) != 0 {
+		t.Errorf("blog 1 still has %d votes, %v", len(remaining), err)
+	}
+	if remaining, err := votes.FindAllByBlog(ctx, 2); err != nil || len(remaining) != 1 {
+		t.Errorf("blog 2 has %d votes, %v; want 1", len(remaining), err)
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"BlogApplication/auth"
+	"BlogApplication/model"
+	"context"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// AuthInterceptor authenticates every RPC by the bearer token in its
+// "authorization" metadata and stores the caller's principal in the context.
+type AuthInterceptor struct {
+	Verifier *auth.Verifier
+	// TrustInternalCallers lets calls without a token through, acting as the
+	// user named in the request payload. It exists for internal services that
+	// don't send tokens yet, and only applies to callers that presented a
+	// verified client certificate or connect from a TrustedNetworks address.
+	TrustInternalCallers bool
+	// TrustClientCertificates treats callers with a verified mutual TLS
+	// client certificate as internal.
+	TrustClientCertificates bool
+	// TrustedNetworks are the networks internal callers connect from.
+	TrustedNetworks []netip.Prefix
+	// PublicMethods are full method names, or prefixes ending in "/", that
+	// don't need a token. A token sent to one is still verified, so signed-in
+	// callers are recognised.
+	PublicMethods []string
+}
+
+func (interceptor *AuthInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := interceptor.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (interceptor *AuthInterceptor) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := interceptor.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return toStatusError(ss.Context(), err)
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (interceptor *AuthInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	token, found := bearerToken(ctx)
+	if !found {
+		if interceptor.isPublic(method) {
+			return ctx, nil
+		}
+		if interceptor.TrustInternalCallers && interceptor.isTrustedPeer(ctx) {
+			return auth.WithTrustedCaller(ctx), nil
+		}
+		return nil, model.Unauthenticated("missing bearer token")
+	}
+
+	principal, err := interceptor.Verifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func (interceptor *AuthInterceptor) isPublic(method string) bool {
+	for _, public := range interceptor.PublicMethods {
+		if method == public || (strings.HasSuffix(public, "/") && strings.HasPrefix(method, public)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrustedPeer reports whether the caller proved it's internal, by its
+// client certificate or by the network it connects from.
+func (interceptor *AuthInterceptor) isTrustedPeer(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	if interceptor.TrustClientCertificates {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			return true
+		}
+	}
+	if p.Addr == nil {
+		return false
+	}
+	addrPort, err := netip.ParseAddrPort(p.Addr.String())
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, network := range interceptor.TrustedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, value := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "Bearer") && token != "" {
+			return strings.TrimSpace(token), true
+		}
+	}
+	return "", false
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
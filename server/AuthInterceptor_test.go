@@ -0,0 +1,152 @@
+package server
+
+import (
+	"BlogApplication/auth"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var testSecret = []byte("test-secret")
+
+func testToken(t *testing.T, subject int64, roles ...string) string {
+	t.Helper()
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	rolesJSON := "[]"
+	if len(roles) > 0 {
+		rolesJSON = fmt.Sprintf("[%q]", roles[0])
+	}
+	signingInput := encode(`{"alg":"HS256","typ":"JWT"}`) + "." +
+		encode(fmt.Sprintf(`{"sub":"%d","roles":%s,"exp":%d}`, subject, rolesJSON, time.Now().Add(time.Hour).Unix()))
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func testInterceptor() *AuthInterceptor {
+	return &AuthInterceptor{
+		Verifier:             &auth.Verifier{HMACSecret: testSecret},
+		TrustInternalCallers: true,
+		TrustedNetworks:      []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		PublicMethods:        []string{"/grpc.health.v1.Health/", BlogMicroservice_FindBlogById_FullMethodName},
+	}
+}
+
+// callContext is an incoming RPC context from addr, with a bearer token
+// when token isn't empty.
+func callContext(addr string, authInfo credentials.AuthInfo, token string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     net.TCPAddrFromAddrPort(netip.MustParseAddrPort(addr)),
+		AuthInfo: authInfo,
+	})
+	md := metadata.MD{}
+	if token != "" {
+		md.Set("authorization", "Bearer "+token)
+	}
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// call runs the interceptor for method and returns the context the handler
+// saw.
+func call(t *testing.T, interceptor *AuthInterceptor, ctx context.Context, method string) (context.Context, error) {
+	t.Helper()
+	var seen context.Context
+	_, err := interceptor.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = ctx
+		return nil, nil
+	})
+	return seen, err
+}
+
+func TestAuthInterceptorVerifiesTokens(t *testing.T) {
+	ctx, err := call(t, testInterceptor(), callContext("203.0.113.5:5000", nil, testToken(t, 7, auth.RoleAdmin)), BlogMicroservice_UpdateBlog_FullMethodName)
+	if err != nil {
+		t.Fatalf("Unary: %v", err)
+	}
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok || principal.UserId != 7 || !principal.HasRole(auth.RoleAdmin) {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	_, err = call(t, testInterceptor(), callContext("203.0.113.5:5000", nil, "not.a.token"), BlogMicroservice_UpdateBlog_FullMethodName)
+	if status.Code(toStatusError(context.Background(), err)) != codes.Unauthenticated {
+		t.Errorf("bad token: got %v, want Unauthenticated", err)
+	}
+}
+
+func TestAuthInterceptorTrustsTokenlessCallersOnlyFromTrustedPeers(t *testing.T) {
+	verified := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}}
+	tests := []struct {
+		name        string
+		interceptor func(*AuthInterceptor)
+		addr        string
+		authInfo    credentials.AuthInfo
+		wantTrusted bool
+	}{
+		{name: "trusted network", addr: "10.1.2.3:5000", wantTrusted: true},
+		{name: "trusted network over IPv6", addr: "[::ffff:10.1.2.3]:5000", wantTrusted: true},
+		{name: "other network", addr: "203.0.113.5:5000"},
+		{name: "client certificate", interceptor: func(i *AuthInterceptor) { i.TrustClientCertificates = true }, addr: "203.0.113.5:5000", authInfo: verified, wantTrusted: true},
+		{name: "client certificate not trusted", addr: "203.0.113.5:5000", authInfo: verified},
+		{name: "trust turned off", interceptor: func(i *AuthInterceptor) { i.TrustInternalCallers = false }, addr: "10.1.2.3:5000"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interceptor := testInterceptor()
+			if test.interceptor != nil {
+				test.interceptor(interceptor)
+			}
+			ctx, err := call(t, interceptor, callContext(test.addr, test.authInfo, ""), BlogMicroservice_UpdateBlog_FullMethodName)
+			if !test.wantTrusted {
+				if status.Code(toStatusError(context.Background(), err)) != codes.Unauthenticated {
+					t.Errorf("got %v, want Unauthenticated", err)
+				}
+				return
+			}
+			if err != nil || !auth.IsTrustedCaller(ctx) {
+				t.Errorf("got %v, trusted %v; want a trusted caller", err, err == nil && auth.IsTrustedCaller(ctx))
+			}
+		})
+	}
+}
+
+func TestAuthInterceptorPublicMethods(t *testing.T) {
+	for _, method := range []string{"/grpc.health.v1.Health/Check", BlogMicroservice_FindBlogById_FullMethodName} {
+		ctx, err := call(t, testInterceptor(), callContext("203.0.113.5:5000", nil, ""), method)
+		if err != nil {
+			t.Errorf("%s without a token: %v", method, err)
+			continue
+		}
+		if _, ok := auth.PrincipalFrom(ctx); ok || auth.IsTrustedCaller(ctx) {
+			t.Errorf("%s: anonymous caller got an identity", method)
+		}
+	}
+
+	// Signed-in callers of a public method are still recognised.
+	ctx, err := call(t, testInterceptor(), callContext("203.0.113.5:5000", nil, testToken(t, 7)), BlogMicroservice_FindBlogById_FullMethodName)
+	if err != nil {
+		t.Fatalf("Unary: %v", err)
+	}
+	if principal, ok := auth.PrincipalFrom(ctx); !ok || principal.UserId != 7 {
+		t.Errorf("principal = %+v, want user 7", principal)
+	}
+
+	if _, err := call(t, testInterceptor(), callContext("203.0.113.5:5000", nil, ""), BlogMicroservice_FindBlogById_FullMethodName+"AndMore"); err == nil {
+		t.Error("a method merely prefixed by a public one needed no token")
+	}
+}
@@ -1,16 +1,17 @@
 package server
 
 import (
+	"BlogApplication/auth"
 	"BlogApplication/dto"
 	"BlogApplication/model"
 	"BlogApplication/service"
+	"BlogApplication/telemetry"
+	"BlogApplication/useCases"
 	"context"
-	"encoding/json"
-	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
-	"github.com/nats-io/nats.go"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -23,7 +24,12 @@ type BlogMicroservice struct {
 	BlogService    *service.BlogService
 	CommentService *service.CommentService
 	ReportService  *service.ReportService
-	NatsConn       *nats.Conn
+	VoteService    *service.VoteService
+
+	DeadLetterService *service.DeadLetterService
+	LoggingService    *service.LoggingService
+
+	Logger *slog.Logger
 }
 
 func (s *BlogMicroservice) FindBlogById(ctx context.Context, req *BlogIdRequest) (*BlogResponse, error) {
@@ -31,60 +37,21 @@ func (s *BlogMicroservice) FindBlogById(ctx context.Context, req *BlogIdRequest)
 	ctx, span := tracer.Start(ctx, "FindBlogById")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.BlogId(req.Id))
+
+	blog, err := s.BlogService.FindVisible(ctx, req.Id)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "FindBlogById failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
-
-	blog, err := s.BlogService.Find(ctx, req.Id)
-
-	var comments []*CommentResponse
-	for _, c := range blog.Comments {
-		comments = append(comments, &CommentResponse{
-			Id:        int32(c.Id),
-			AuthorId:  c.AuthorId,
-			BlogId:    c.BlogId,
-			CreatedAt: timestamppb.New(c.CreatedAt),
-			UpdatedAt: timestamppb.New(c.UpdatedAt),
-			Text:      c.Text,
-		})
-	}
-	if comments == nil {
-		comments = []*CommentResponse{}
-	}
-
-	var votes []*VoteResponse
-	for _, v := range blog.Votes {
-		votes = append(votes, &VoteResponse{
-			Id:       int32(v.Id),
-			UserId:   v.UserId,
-			BlogId:   v.BlogId,
-			VoteType: string(v.VoteType),
-		})
-	}
-	if votes == nil {
-		votes = []*VoteResponse{}
+	blog.Votes, err = s.VoteService.FindAllByBlog(ctx, req.Id)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindBlogById failed")
+		return nil, err
 	}
 
 	span.SetStatus(codes.Ok, "FindBlogById successful")
-	return &BlogResponse{
-		Id:            int32(blog.Id),
-		Title:         blog.Title,
-		Description:   blog.Description,
-		Date:          timestamppb.New(blog.Date),
-		Status:        string(blog.Status),
-		AuthorId:      blog.AuthorId,
-		Comments:      comments,
-		Votes:         votes,
-		Visibility:    string(blog.Visibility),
-		VoteCount:     blog.VoteCount,
-		UpvoteCount:   blog.UpvoteCount,
-		DownvoteCount: blog.DownvoteCount,
-		BlogTopic:     string(blog.BlogTopic),
-	}, err
+	return toBlogResponse(blog), nil
 }
 
 func (s *BlogMicroservice) FindBlogsByType(ctx context.Context, req *TypeRequest) (*BlogListResponse, error) {
@@ -92,144 +59,68 @@ func (s *BlogMicroservice) FindBlogsByType(ctx context.Context, req *TypeRequest
 	ctx, span := tracer.Start(ctx, "FindBlogsByType")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.Topic(req.Type), telemetry.PageSize(int64(req.PageSize)))
+
+	topicType, err := model.ParseBlogTopicType(req.Type)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "FindBlogsByType failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
-
-	topicType, _ := model.ParseBlogTopicType(req.Type)
-	blogsByTopic, err := s.BlogService.GetBlogsByTopic(ctx, topicType)
-
-	var blogs []*BlogResponse
-	for _, b := range blogsByTopic {
-
-		var comments []*CommentResponse
-		for _, c := range b.Comments {
-			comments = append(comments, &CommentResponse{
-				Id:        int32(c.Id),
-				AuthorId:  c.AuthorId,
-				BlogId:    c.BlogId,
-				CreatedAt: timestamppb.New(c.CreatedAt),
-				UpdatedAt: timestamppb.New(c.UpdatedAt),
-				Text:      c.Text,
-			})
-		}
-		if comments == nil {
-			comments = []*CommentResponse{}
-		}
-
-		var votes []*VoteResponse
-		for _, v := range b.Votes {
-			votes = append(votes, &VoteResponse{
-				Id:       int32(v.Id),
-				UserId:   v.UserId,
-				BlogId:   v.BlogId,
-				VoteType: string(v.VoteType),
-			})
-		}
-		if votes == nil {
-			votes = []*VoteResponse{}
-		}
-
-		blogs = append(blogs, &BlogResponse{
-			Id:            int32(b.Id),
-			Title:         b.Title,
-			Description:   b.Description,
-			Date:          timestamppb.New(b.Date),
-			Status:        string(b.Status),
-			AuthorId:      b.AuthorId,
-			Comments:      comments,
-			Votes:         votes,
-			Visibility:    string(b.Visibility),
-			VoteCount:     b.VoteCount,
-			UpvoteCount:   b.UpvoteCount,
-			DownvoteCount: b.DownvoteCount,
-			BlogTopic:     string(b.BlogTopic),
-		})
+	viewer, err := s.BlogService.Viewer(ctx, req.IncludeHidden)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindBlogsByType failed")
+		return nil, err
 	}
-	if blogs == nil {
-		blogs = []*BlogResponse{}
+	page, err := s.BlogService.GetBlogsByTopicPage(ctx, topicType, viewer, useCases.PageRequest{Size: int64(req.PageSize), Token: req.PageToken})
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error fetching blogs", "error", err)
+		span.SetStatus(codes.Error, "FindBlogsByType failed")
+		return nil, err
+	}
+
+	blogs := []*BlogResponse{}
+	for _, b := range page.Items {
+		blogs = append(blogs, toBlogResponse(&b))
 	}
 
 	span.SetStatus(codes.Ok, "FindBlogsByType successful")
 	return &BlogListResponse{
-		Blogs: blogs,
-	}, err
+		Blogs:         blogs,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}, nil
 }
 
-func (s *BlogMicroservice) FindPublishedBlogs(ctx context.Context, req *Empty) (*BlogListResponse, error) {
+func (s *BlogMicroservice) FindPublishedBlogs(ctx context.Context, req *PageRequest) (*BlogListResponse, error) {
 	tracer := otel.Tracer("controller")
 	ctx, span := tracer.Start(ctx, "FindPublishedBlogs")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.PageSize(int64(req.PageSize)))
+
+	viewer, err := s.BlogService.Viewer(ctx, req.IncludeHidden)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "FindPublishedBlogs failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
-
-	blogsByTopic, err := s.BlogService.FindAllPublished(ctx)
-
-	var blogs []*BlogResponse
-	for _, b := range blogsByTopic {
-
-		var comments []*CommentResponse
-		for _, c := range b.Comments {
-			comments = append(comments, &CommentResponse{
-				Id:        int32(c.Id),
-				AuthorId:  c.AuthorId,
-				BlogId:    c.BlogId,
-				CreatedAt: timestamppb.New(c.CreatedAt),
-				UpdatedAt: timestamppb.New(c.UpdatedAt),
-				Text:      c.Text,
-			})
-		}
-		if comments == nil {
-			comments = []*CommentResponse{}
-		}
-
-		var votes []*VoteResponse
-		for _, v := range b.Votes {
-			votes = append(votes, &VoteResponse{
-				Id:       int32(v.Id),
-				UserId:   v.UserId,
-				BlogId:   v.BlogId,
-				VoteType: string(v.VoteType),
-			})
-		}
-		if votes == nil {
-			votes = []*VoteResponse{}
-		}
-
-		blogs = append(blogs, &BlogResponse{
-			Id:            int32(b.Id),
-			Title:         b.Title,
-			Description:   b.Description,
-			Date:          timestamppb.New(b.Date),
-			Status:        string(b.Status),
-			AuthorId:      b.AuthorId,
-			Comments:      comments,
-			Votes:         votes,
-			Visibility:    string(b.Visibility),
-			VoteCount:     b.VoteCount,
-			UpvoteCount:   b.UpvoteCount,
-			DownvoteCount: b.DownvoteCount,
-			BlogTopic:     string(b.BlogTopic),
-		})
+	page, err := s.BlogService.FindAllPublishedPage(ctx, viewer, useCases.PageRequest{Size: int64(req.PageSize), Token: req.PageToken})
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error fetching blogs", "error", err)
+		span.SetStatus(codes.Error, "FindPublishedBlogs failed")
+		return nil, err
 	}
-	if blogs == nil {
-		blogs = []*BlogResponse{}
+
+	blogs := []*BlogResponse{}
+	for _, b := range page.Items {
+		blogs = append(blogs, toBlogResponse(&b))
 	}
 
 	span.SetStatus(codes.Ok, "FindPublishedBlogs successful")
 	return &BlogListResponse{
-		Blogs: blogs,
-	}, err
+		Blogs:         blogs,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}, nil
 }
 
 func (s *BlogMicroservice) FindBlogsByAuthor(ctx context.Context, req *AuthorIdRequest) (*BlogListResponse, error) {
@@ -237,71 +128,31 @@ func (s *BlogMicroservice) FindBlogsByAuthor(ctx context.Context, req *AuthorIdR
 	ctx, span := tracer.Start(ctx, "FindBlogsByAuthor")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.UserId(req.Id), telemetry.PageSize(int64(req.PageSize)))
+
+	viewer, err := s.BlogService.Viewer(ctx, req.IncludeHidden)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "FindBlogsByAuthor failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
-
-	blogsByTopic, err := s.BlogService.FindAllByAuthor(ctx, req.Id)
-
-	var blogs []*BlogResponse
-	for _, b := range blogsByTopic {
-
-		var comments []*CommentResponse
-		for _, c := range b.Comments {
-			comments = append(comments, &CommentResponse{
-				Id:        int32(c.Id),
-				AuthorId:  c.AuthorId,
-				BlogId:    c.BlogId,
-				CreatedAt: timestamppb.New(c.CreatedAt),
-				UpdatedAt: timestamppb.New(c.UpdatedAt),
-				Text:      c.Text,
-			})
-		}
-		if comments == nil {
-			comments = []*CommentResponse{}
-		}
-
-		var votes []*VoteResponse
-		for _, v := range b.Votes {
-			votes = append(votes, &VoteResponse{
-				Id:       int32(v.Id),
-				UserId:   v.UserId,
-				BlogId:   v.BlogId,
-				VoteType: string(v.VoteType),
-			})
-		}
-		if votes == nil {
-			votes = []*VoteResponse{}
-		}
-
-		blogs = append(blogs, &BlogResponse{
-			Id:            int32(b.Id),
-			Title:         b.Title,
-			Description:   b.Description,
-			Date:          timestamppb.New(b.Date),
-			Status:        string(b.Status),
-			AuthorId:      b.AuthorId,
-			Comments:      comments,
-			Votes:         votes,
-			Visibility:    string(b.Visibility),
-			VoteCount:     b.VoteCount,
-			UpvoteCount:   b.UpvoteCount,
-			DownvoteCount: b.DownvoteCount,
-			BlogTopic:     string(b.BlogTopic),
-		})
+	page, err := s.BlogService.FindAllByAuthorPage(ctx, req.Id, viewer, useCases.PageRequest{Size: int64(req.PageSize), Token: req.PageToken})
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error fetching blogs", "error", err)
+		span.SetStatus(codes.Error, "FindBlogsByAuthor failed")
+		return nil, err
 	}
-	if blogs == nil {
-		blogs = []*BlogResponse{}
+
+	blogs := []*BlogResponse{}
+	for _, b := range page.Items {
+		blogs = append(blogs, toBlogResponse(&b))
 	}
 
 	span.SetStatus(codes.Ok, "FindBlogsByAuthor successful")
 	return &BlogListResponse{
-		Blogs: blogs,
-	}, err
+		Blogs:         blogs,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}, nil
 }
 
 func (s *BlogMicroservice) CreateBlog(ctx context.Context, req *BlogCreationRequest) (*StringMessage, error) {
@@ -309,61 +160,240 @@ func (s *BlogMicroservice) CreateBlog(ctx context.Context, req *BlogCreationRequ
 	ctx, span := tracer.Start(ctx, "CreateBlog")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.UserId(req.AuthorId), telemetry.Topic(req.BlogTopic), telemetry.Status(req.Status), telemetry.Text("blog.title", req.Title))
+
+	authorId, err := auth.ActorId(ctx, req.AuthorId)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "CreateBlog failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
 
 	blog := &model.Blog{
 		Title:       req.Title,
 		Description: req.Description,
-		AuthorId:    req.AuthorId,
+		AuthorId:    authorId,
 		BlogTopic:   model.BlogTopicType(req.BlogTopic),
+		Status:      model.BlogStatus(req.Status),
 		Date:        time.Now(),
 	}
 
 	err = s.BlogService.Create(ctx, blog)
 
 	if err != nil {
-		fmt.Println("Error while creating a new blog:", err)
-		message := &StringMessage{Message: "Error while creating blog"}
+		s.Logger.WarnContext(ctx, "Error while creating a new blog", "error", err)
 		span.SetStatus(codes.Error, "CreateBlog failed")
-		return message, err
+		return nil, err
 	}
 
 	span.SetStatus(codes.Ok, "CreateBlog successful")
 	message := &StringMessage{Message: "Successfully created blog"}
-	return message, err
+	return message, nil
 }
 
-func (s *BlogMicroservice) DeleteBlog(ctx context.Context, req *BlogIdRequest) (*StringMessage, error) {
+func (s *BlogMicroservice) UpdateBlog(ctx context.Context, req *BlogUpdateRequest) (*BlogResponse, error) {
 	tracer := otel.Tracer("controller")
-	ctx, span := tracer.Start(ctx, "DeleteBlog")
+	ctx, span := tracer.Start(ctx, "UpdateBlog")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.Id), telemetry.UserId(req.EditorId), telemetry.Text("blog.title", req.Title))
+
+	blog := &model.Blog{
+		Title:       req.Title,
+		Description: req.Description,
+		BlogTopic:   model.BlogTopicType(req.BlogTopic),
+		Visibility:  model.BlogVisibilityPolicy(req.Visibility),
+	}
+
+	editorId, err := auth.ActorId(ctx, req.EditorId)
+	if err != nil {
+		span.SetStatus(codes.Error, "UpdateBlog failed")
+		return nil, err
+	}
+
+	updatedBlog, err := s.BlogService.Update(ctx, req.Id, editorId, blog, req.UpdateMask.GetPaths())
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error updating blog", "error", err)
+		span.SetStatus(codes.Error, "UpdateBlog failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "UpdateBlog successful")
+	return toBlogResponse(updatedBlog), nil
+}
+
+func (s *BlogMicroservice) PublishBlog(ctx context.Context, req *BlogIdRequest) (*BlogResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "PublishBlog")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.Id))
+
+	blog, err := s.BlogService.Publish(ctx, req.Id)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error in PublishBlog", "error", err)
+		span.SetStatus(codes.Error, "PublishBlog failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "PublishBlog successful")
+	return toBlogResponse(blog), nil
+}
+
+func (s *BlogMicroservice) UnpublishBlog(ctx context.Context, req *BlogIdRequest) (*BlogResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "UnpublishBlog")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.Id))
+
+	blog, err := s.BlogService.Unpublish(ctx, req.Id)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error in UnpublishBlog", "error", err)
+		span.SetStatus(codes.Error, "UnpublishBlog failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "UnpublishBlog successful")
+	return toBlogResponse(blog), nil
+}
+
+func (s *BlogMicroservice) SchedulePublish(ctx context.Context, req *SchedulePublishRequest) (*BlogResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SchedulePublish")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.BlogId))
+
+	blog, err := s.BlogService.SchedulePublish(ctx, req.BlogId, req.PublishAt.AsTime())
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error in SchedulePublish", "error", err)
+		span.SetStatus(codes.Error, "SchedulePublish failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "SchedulePublish successful")
+	return toBlogResponse(blog), nil
+}
+
+func (s *BlogMicroservice) ListBlogRevisions(ctx context.Context, req *BlogIdRequest) (*BlogRevisionListResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ListBlogRevisions")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.Id))
+
+	revisions, err := s.BlogService.FindRevisions(ctx, req.Id)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error fetching blog revisions", "error", err)
+		span.SetStatus(codes.Error, "ListBlogRevisions failed")
+		return nil, err
+	}
+
+	response := []*BlogRevisionResponse{}
+	for _, revision := range revisions {
+		response = append(response, toBlogRevisionResponse(&revision))
+	}
+
+	span.SetStatus(codes.Ok, "ListBlogRevisions successful")
+	return &BlogRevisionListResponse{Revisions: response}, nil
+}
+
+func (s *BlogMicroservice) GetBlogRevision(ctx context.Context, req *BlogRevisionRequest) (*BlogRevisionResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetBlogRevision")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.BlogId), telemetry.RevisionId(req.RevisionId))
+
+	revision, err := s.BlogService.FindRevision(ctx, req.BlogId, req.RevisionId)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error fetching blog revision", "error", err)
+		span.SetStatus(codes.Error, "GetBlogRevision failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "GetBlogRevision successful")
+	return toBlogRevisionResponse(revision), nil
+}
+
+func (s *BlogMicroservice) RestoreBlogRevision(ctx context.Context, req *RestoreBlogRevisionRequest) (*BlogResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RestoreBlogRevision")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.BlogId), telemetry.RevisionId(req.RevisionId), telemetry.UserId(req.EditorId))
+
+	editorId, err := auth.ActorId(ctx, req.EditorId)
+	if err != nil {
+		span.SetStatus(codes.Error, "RestoreBlogRevision failed")
+		return nil, err
+	}
+
+	blog, err := s.BlogService.RestoreRevision(ctx, req.BlogId, req.RevisionId, editorId)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error restoring blog revision", "error", err)
+		span.SetStatus(codes.Error, "RestoreBlogRevision failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "RestoreBlogRevision successful")
+	return toBlogResponse(blog), nil
+}
+
+func (s *BlogMicroservice) DiffBlogRevisions(ctx context.Context, req *BlogRevisionDiffRequest) (*BlogRevisionDiffResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DiffBlogRevisions")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.BlogId(req.BlogId), attribute.Int64("blog.revision.from", req.FromRevisionId), attribute.Int64("blog.revision.to", req.ToRevisionId))
+
+	diff, err := s.BlogService.DiffRevisions(ctx, req.BlogId, req.FromRevisionId, req.ToRevisionId)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		s.Logger.WarnContext(ctx, "Error diffing blog revisions", "error", err)
+		span.SetStatus(codes.Error, "DiffBlogRevisions failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
 
-	err = s.BlogService.Delete(ctx, req.Id)
+	fields := []*FieldDiff{}
+	for _, field := range diff {
+		lines := []*DiffLine{}
+		for _, line := range field.Lines {
+			lines = append(lines, &DiffLine{
+				Op:      string(line.Op),
+				Text:    line.Text,
+				OldLine: int32(line.OldLine),
+				NewLine: int32(line.NewLine),
+			})
+		}
+		fields = append(fields, &FieldDiff{Field: field.Field, Lines: lines})
+	}
+
+	span.SetStatus(codes.Ok, "DiffBlogRevisions successful")
+	return &BlogRevisionDiffResponse{
+		FromRevisionId: req.FromRevisionId,
+		ToRevisionId:   req.ToRevisionId,
+		Fields:         fields,
+	}, nil
+}
+
+func (s *BlogMicroservice) DeleteBlog(ctx context.Context, req *BlogIdRequest) (*StringMessage, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeleteBlog")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.Id))
+
+	err := s.BlogService.Delete(ctx, req.Id)
 
 	if err != nil {
-		fmt.Println("Error while deleting a blog:", err)
-		message := &StringMessage{Message: "Error while deleting a blog"}
+		s.Logger.WarnContext(ctx, "Error while deleting a blog", "error", err)
 		span.SetStatus(codes.Error, "DeleteBlog failed")
-		return message, err
+		return nil, err
 	}
 
 	message := &StringMessage{Message: "Successfully deleted a blog"}
 	span.SetStatus(codes.Ok, "DeleteBlog successful")
-	return message, err
+	return message, nil
 }
 
 func (s *BlogMicroservice) BlockBlog(ctx context.Context, req *BlogIdRequest) (*StringMessage, error) {
@@ -371,26 +401,19 @@ func (s *BlogMicroservice) BlockBlog(ctx context.Context, req *BlogIdRequest) (*
 	ctx, span := tracer.Start(ctx, "BlockBlog")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil, err
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(req.Id))
 
-	err = s.BlogService.Block(ctx, req.Id)
+	err := s.BlogService.Block(ctx, req.Id)
 
 	if err != nil {
-		fmt.Println("Error while blocking a blog:", err)
-		message := &StringMessage{Message: "Error while blocking a blog"}
+		s.Logger.WarnContext(ctx, "Error while blocking a blog", "error", err)
 		span.SetStatus(codes.Error, "BlockBlog failed")
-		return message, err
+		return nil, err
 	}
 
 	message := &StringMessage{Message: "Successfully blocked a blog"}
 	span.SetStatus(codes.Ok, "BlockBlog successful")
-	return message, err
+	return message, nil
 }
 
 // func (s *BlogMicroservice) CreateComment(ctx context.Context, req *CommentCreationRequest) (*CommentResponse, error) {
@@ -435,53 +458,33 @@ func (s *BlogMicroservice) CreateComment(ctx context.Context, req *CommentCreati
 	ctx, span := tracer.Start(ctx, "CreateComment")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.BlogId(req.BlogId), telemetry.UserId(req.AuthorId), attribute.Int64("comment.parent_id", req.ParentId), telemetry.Text("comment.text", req.Text))
+
+	authorId, err := auth.ActorId(ctx, req.AuthorId)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "CreateComment failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
 
 	comment := dto.CommentRequestDTO{
-		AuthorId:  req.AuthorId,
+		AuthorId:  authorId,
 		BlogId:    req.BlogId,
 		CreatedAt: req.CreatedAt.AsTime(),
 		Text:      req.Text,
 	}
+	if req.ParentId > 0 {
+		parentId := int(req.ParentId)
+		comment.ParentId = &parentId
+	}
 	createdComment, err := s.CommentService.Create(ctx, &comment)
 	if err != nil {
-		log.Printf("Error creating comment: %v", err)
+		s.Logger.WarnContext(ctx, "Error creating comment", "error", err)
 		span.SetStatus(codes.Error, "CreateComment failed")
 		return nil, err
 	}
-	blog, err := s.BlogService.Find(ctx, req.BlogId)
-
-	// Publish event to NATS
-	log.Printf("SENDING NATS REQ")
-	event := map[string]interface{}{
-		"user_id":    req.AuthorId,
-		"author_id":  blog.AuthorId,
-		"comment_id": createdComment.Id,
-	}
-	eventData, _ := json.Marshal(event)
-	err = s.NatsConn.Publish("comment.created", eventData)
-	if err != nil {
-		log.Printf("ERROR SENDING NATS REQ")
-		log.Printf("Error publishing event: %v", err)
-		span.SetStatus(codes.Error, "Event publication failed")
-		return nil, err
-	}
 
 	span.SetStatus(codes.Ok, "CreateComment successful")
-	return &CommentResponse{
-		Id:        int32(createdComment.Id),
-		AuthorId:  int64(createdComment.AuthorId),
-		BlogId:    int64(createdComment.BlogId),
-		CreatedAt: timestamppb.New(createdComment.CreatedAt),
-		UpdatedAt: timestamppb.New(createdComment.UpdatedAt),
-		Text:      createdComment.Text,
-	}, err
+	return toCommentResponse(createdComment), nil
 }
 
 func (s *BlogMicroservice) UpdateComment(ctx context.Context, req *CommentUpdateRequest) (*StringMessage, error) {
@@ -489,23 +492,17 @@ func (s *BlogMicroservice) UpdateComment(ctx context.Context, req *CommentUpdate
 	ctx, span := tracer.Start(ctx, "UpdateComment")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil, err
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.CommentId(req.Id), telemetry.Text("comment.text", req.Text))
 
 	comment := &dto.CommentUpdateDto{
 		ID:   req.Id,
 		Text: req.Text,
 	}
-	err = s.CommentService.Update(ctx, comment)
+	err := s.CommentService.Update(ctx, comment)
 	if err != nil {
-		log.Printf("Error updating comment: %v", err)
+		s.Logger.WarnContext(ctx, "Error updating comment", "error", err)
 		span.SetStatus(codes.Error, "UpdateComment failed")
-		return &StringMessage{Message: "Error updating comment"}, err
+		return nil, err
 	}
 
 	span.SetStatus(codes.Ok, "UpdateComment successful")
@@ -517,95 +514,98 @@ func (s *BlogMicroservice) DeleteComment(ctx context.Context, req *CommentIdRequ
 	ctx, span := tracer.Start(ctx, "DeleteComment")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil, err
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.CommentId(req.Id))
 
-	err = s.CommentService.Delete(ctx, req.Id)
+	err := s.CommentService.Delete(ctx, req.Id)
 	if err != nil {
-		log.Printf("Error deleting comment: %v", err)
+		s.Logger.WarnContext(ctx, "Error deleting comment", "error", err)
 		span.SetStatus(codes.Error, "DeleteComment failed")
-		return &StringMessage{Message: "Error deleting comment"}, err
+		return nil, err
 	}
 
 	span.SetStatus(codes.Ok, "DeleteComment successful")
 	return &StringMessage{Message: "Successfully deleted comment"}, nil
 }
 
-func (s *BlogMicroservice) GetAllComments(ctx context.Context, req *Empty) (*CommentListResponse, error) {
+func (s *BlogMicroservice) GetAllComments(ctx context.Context, req *PageRequest) (*CommentListResponse, error) {
 	tracer := otel.Tracer("controller")
 	ctx, span := tracer.Start(ctx, "GetAllComments")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil, err
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.PageSize(int64(req.PageSize)))
 
-	comments, err := s.CommentService.GetAll(ctx)
+	page, err := s.CommentService.GetAllPage(ctx, useCases.PageRequest{Size: int64(req.PageSize), Token: req.PageToken})
 	if err != nil {
-		log.Printf("Error fetching all comments: %v", err)
+		s.Logger.WarnContext(ctx, "Error fetching all comments", "error", err)
 		span.SetStatus(codes.Error, "GetAllComments failed")
 		return nil, err
 	}
 
-	var response []*CommentResponse
-	for _, comment := range comments {
-		response = append(response, &CommentResponse{
-			Id:        int32(comment.Id),
-			AuthorId:  comment.AuthorId,
-			BlogId:    comment.BlogId,
-			CreatedAt: timestamppb.New(comment.CreatedAt),
-			UpdatedAt: timestamppb.New(comment.UpdatedAt),
-			Text:      comment.Text,
-		})
+	response := []*CommentResponse{}
+	for _, comment := range page.Items {
+		response = append(response, toCommentResponse(&comment))
 	}
 
 	span.SetStatus(codes.Ok, "GetAllComments successful")
-	return &CommentListResponse{Comments: response}, nil
+	return &CommentListResponse{
+		Comments:      response,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}, nil
 }
 
-func (s *BlogMicroservice) GetAllBlogComments(ctx context.Context, req *BlogIdRequest) (*CommentListResponse, error) {
+func (s *BlogMicroservice) GetAllBlogComments(ctx context.Context, req *BlogPageRequest) (*CommentListResponse, error) {
 	tracer := otel.Tracer("controller")
 	ctx, span := tracer.Start(ctx, "GetAllBlogComments")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.BlogId(req.Id), telemetry.PageSize(int64(req.PageSize)))
+
+	page, err := s.CommentService.GetAllBlogCommentsPage(ctx, req.Id, useCases.PageRequest{Size: int64(req.PageSize), Token: req.PageToken})
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		s.Logger.WarnContext(ctx, "Error fetching comments for blog", "error", err)
+		span.SetStatus(codes.Error, "GetAllBlogComments failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
 
-	comments, err := s.CommentService.GetAllBlogComments(ctx, req.Id)
+	response := []*CommentResponse{}
+	for _, comment := range page.Items {
+		response = append(response, toCommentResponse(&comment))
+	}
+
+	span.SetStatus(codes.Ok, "GetAllBlogComments successful")
+	return &CommentListResponse{
+		Comments:      response,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}, nil
+}
+
+func (s *BlogMicroservice) GetCommentThread(ctx context.Context, req *CommentThreadRequest) (*CommentThreadResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCommentThread")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.BlogId), attribute.Int64("comment.root_id", req.RootCommentId))
+
+	threads, err := s.CommentService.GetThread(ctx, req.BlogId, req.RootCommentId)
 	if err != nil {
-		log.Printf("Error fetching comments for blog: %v", err)
-		span.SetStatus(codes.Error, "GetAllBlogComments failed")
+		s.Logger.WarnContext(ctx, "Error fetching comment thread", "error", err)
+		span.SetStatus(codes.Error, "GetCommentThread failed")
 		return nil, err
 	}
 
-	var response []*CommentResponse
-	for _, comment := range comments {
-		response = append(response, &CommentResponse{
-			Id:        int32(comment.Id),
-			AuthorId:  comment.AuthorId,
-			BlogId:    comment.BlogId,
-			CreatedAt: timestamppb.New(comment.CreatedAt),
-			UpdatedAt: timestamppb.New(comment.UpdatedAt),
-			Text:      comment.Text,
-		})
+	response := &CommentThreadResponse{Threads: []*CommentNode{}, Comments: []*CommentResponse{}}
+	if req.Flatten {
+		for _, comment := range model.FlattenCommentTree(threads) {
+			response.Comments = append(response.Comments, toCommentResponse(&comment))
+		}
+	} else {
+		response.Threads = toCommentNodes(threads)
 	}
 
-	span.SetStatus(codes.Ok, "GetAllBlogComments successful")
-	return &CommentListResponse{Comments: response}, nil
+	span.SetStatus(codes.Ok, "GetCommentThread successful")
+	return response, nil
 }
 
 func (s *BlogMicroservice) CreateReport(ctx context.Context, req *ReportRequest) (*StringMessage, error) {
@@ -613,52 +613,49 @@ func (s *BlogMicroservice) CreateReport(ctx context.Context, req *ReportRequest)
 	ctx, span := tracer.Start(ctx, "CreateReport")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.BlogId(req.BlogId), telemetry.UserId(req.UserId), telemetry.Text("report.reason", req.Reason))
+
+	userId, err := auth.ActorId(ctx, req.UserId)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "CreateReport failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
 
 	report := &model.Report{
 		BlogId: int(req.BlogId),
-		UserId: int(req.UserId),
+		UserId: int(userId),
 		Reason: req.Reason,
 	}
 
 	err = s.ReportService.Create(ctx, report)
 
 	if err != nil {
-		fmt.Println("Error while creating a new report:", err)
-		message := &StringMessage{Message: "Error while creating report"}
+		s.Logger.WarnContext(ctx, "Error while creating a new report", "error", err)
 		span.SetStatus(codes.Error, "CreateReport failed")
-		return message, err
+		return nil, err
 	}
 
 	message := &StringMessage{Message: "Successfully created report"}
 	span.SetStatus(codes.Ok, "CreateReport successful")
-	return message, err
+	return message, nil
 }
 
-func (s *BlogMicroservice) FindReportsByBlog(ctx context.Context, req *BlogIdRequest) (*ReportListResponse, error) {
+func (s *BlogMicroservice) FindReportsByBlog(ctx context.Context, req *BlogPageRequest) (*ReportListResponse, error) {
 	tracer := otel.Tracer("controller")
 	ctx, span := tracer.Start(ctx, "FindReportsByBlog")
 	defer span.End()
 
-	reqData, err := json.Marshal(req)
+	span.SetAttributes(telemetry.BlogId(req.Id), telemetry.PageSize(int64(req.PageSize)))
+
+	page, err := s.ReportService.FindAllByBlogPage(ctx, req.Id, useCases.PageRequest{Size: int64(req.PageSize), Token: req.PageToken})
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		s.Logger.WarnContext(ctx, "Error fetching reports for blog", "error", err)
+		span.SetStatus(codes.Error, "FindReportsByBlog failed")
 		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
-
-	reportsByBlog, err := s.ReportService.FindAllByBlog(ctx, req.Id)
-
-	var reports []*ReportResponse
-	for _, r := range reportsByBlog {
 
+	reports := []*ReportResponse{}
+	for _, r := range page.Items {
 		reports = append(reports, &ReportResponse{
 			Id:     int64(r.Id),
 			UserId: int64(r.UserId),
@@ -666,14 +663,13 @@ func (s *BlogMicroservice) FindReportsByBlog(ctx context.Context, req *BlogIdReq
 			Reason: r.Reason,
 		})
 	}
-	if reports == nil {
-		reports = []*ReportResponse{}
-	}
 
 	span.SetStatus(codes.Ok, "FindReportsByBlog successful")
 	return &ReportListResponse{
-		Reports: reports,
-	}, err
+		Reports:       reports,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}, nil
 }
 
 func (s *BlogMicroservice) Vote(ctx context.Context, req *VoteRequest) (*StringMessage, error) {
@@ -681,17 +677,208 @@ func (s *BlogMicroservice) Vote(ctx context.Context, req *VoteRequest) (*StringM
 	ctx, span := tracer.Start(ctx, "Vote")
 	defer span.End()
 
-	reqData, jsonError := json.Marshal(req)
-	if jsonError != nil {
-		span.RecordError(jsonError)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil, jsonError
+	span.SetAttributes(telemetry.BlogId(req.BlogId), telemetry.UserId(req.UserId), telemetry.VoteType(req.VoteType))
+
+	voteType, err := model.ParseVoteType(req.VoteType)
+	if err != nil {
+		span.SetStatus(codes.Error, "Vote failed")
+		return nil, err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
 
-	_, err := s.BlogService.SetVote(ctx, req.BlogId, req.UserId, model.ParseVoteType(req.VoteType))
+	userId, err := auth.ActorId(ctx, req.UserId)
+	if err != nil {
+		span.SetStatus(codes.Error, "Vote failed")
+		return nil, err
+	}
+
+	_, err = s.VoteService.Vote(ctx, req.BlogId, userId, voteType)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error voting", "error", err)
+		span.SetStatus(codes.Error, "Vote failed")
+		return nil, err
+	}
 
 	message := &StringMessage{Message: "Successfully created voted"}
 	span.SetStatus(codes.Ok, "Vote successful")
-	return message, err
+	return message, nil
+}
+
+func (s *BlogMicroservice) RemoveVote(ctx context.Context, req *RemoveVoteRequest) (*BlogResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RemoveVote")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(req.BlogId), telemetry.UserId(req.UserId))
+
+	userId, err := auth.ActorId(ctx, req.UserId)
+	if err != nil {
+		span.SetStatus(codes.Error, "RemoveVote failed")
+		return nil, err
+	}
+
+	blog, err := s.VoteService.RemoveVote(ctx, req.BlogId, userId)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error removing vote", "error", err)
+		span.SetStatus(codes.Error, "RemoveVote failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "RemoveVote successful")
+	return toBlogResponse(blog), nil
+}
+
+func (s *BlogMicroservice) ListDeadLetters(ctx context.Context, req *DeadLetterListRequest) (*DeadLetterListResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ListDeadLetters")
+	defer span.End()
+
+	span.SetAttributes(telemetry.DeadLetterSeq(req.StartSequence), telemetry.PageSize(int64(req.PageSize)))
+
+	deadLetters, next, err := s.DeadLetterService.List(ctx, req.StartSequence, int(req.PageSize))
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error listing dead letters", "error", err)
+		span.SetStatus(codes.Error, "ListDeadLetters failed")
+		return nil, err
+	}
+
+	response := []*DeadLetter{}
+	for _, deadLetter := range deadLetters {
+		response = append(response, &DeadLetter{
+			Sequence:   deadLetter.Sequence,
+			Subject:    deadLetter.Subject,
+			Consumer:   deadLetter.Consumer,
+			Error:      deadLetter.Error,
+			Deliveries: int32(deadLetter.Deliveries),
+			FailedAt:   timestamppb.New(deadLetter.FailedAt),
+			Data:       deadLetter.Data,
+		})
+	}
+
+	span.SetStatus(codes.Ok, "ListDeadLetters successful")
+	return &DeadLetterListResponse{
+		DeadLetters:  response,
+		NextSequence: next,
+	}, nil
+}
+
+func (s *BlogMicroservice) ReplayDeadLetter(ctx context.Context, req *DeadLetterRequest) (*StringMessage, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ReplayDeadLetter")
+	defer span.End()
+
+	span.SetAttributes(telemetry.DeadLetterSeq(req.Sequence))
+
+	err := s.DeadLetterService.Replay(ctx, req.Sequence)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error replaying dead letter", "error", err)
+		span.SetStatus(codes.Error, "ReplayDeadLetter failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "ReplayDeadLetter successful")
+	return &StringMessage{Message: "Successfully replayed dead letter"}, nil
+}
+
+func toBlogResponse(blog *model.Blog) *BlogResponse {
+	comments := []*CommentResponse{}
+	for _, c := range blog.Comments {
+		comments = append(comments, toCommentResponse(&c))
+	}
+
+	votes := []*VoteResponse{}
+	for _, v := range blog.Votes {
+		votes = append(votes, &VoteResponse{
+			Id:       int32(v.Id),
+			UserId:   v.UserId,
+			BlogId:   v.BlogId,
+			VoteType: string(v.VoteType),
+		})
+	}
+
+	var publishAt *timestamppb.Timestamp
+	if blog.PublishAt != nil {
+		publishAt = timestamppb.New(*blog.PublishAt)
+	}
+
+	return &BlogResponse{
+		Id:            int32(blog.Id),
+		Title:         blog.Title,
+		Description:   blog.Description,
+		Date:          timestamppb.New(blog.Date),
+		Status:        string(blog.Status),
+		AuthorId:      blog.AuthorId,
+		Comments:      comments,
+		Votes:         votes,
+		Visibility:    string(blog.Visibility),
+		VoteCount:     blog.VoteCount,
+		UpvoteCount:   blog.UpvoteCount,
+		DownvoteCount: blog.DownvoteCount,
+		BlogTopic:     string(blog.BlogTopic),
+		PublishAt:     publishAt,
+		CommentCount:  blog.CommentCount,
+	}
+}
+
+func toCommentResponse(comment *model.Comment) *CommentResponse {
+	var parentId int64
+	if comment.ParentId != nil {
+		parentId = int64(*comment.ParentId)
+	}
+
+	return &CommentResponse{
+		Id:        int32(comment.Id),
+		AuthorId:  comment.AuthorId,
+		BlogId:    comment.BlogId,
+		CreatedAt: timestamppb.New(comment.CreatedAt),
+		UpdatedAt: timestamppb.New(comment.UpdatedAt),
+		Text:      comment.Text,
+		ParentId:  parentId,
+		Depth:     int32(comment.Depth),
+		Deleted:   comment.Deleted,
+	}
+}
+
+func toCommentNodes(nodes []*model.CommentNode) []*CommentNode {
+	response := []*CommentNode{}
+	for _, node := range nodes {
+		response = append(response, &CommentNode{
+			Comment: toCommentResponse(&node.Comment),
+			Replies: toCommentNodes(node.Replies),
+		})
+	}
+	return response
+}
+
+func toBlogRevisionResponse(revision *model.BlogRevision) *BlogRevisionResponse {
+	return &BlogRevisionResponse{
+		Id:          int64(revision.Id),
+		BlogId:      revision.BlogId,
+		EditorId:    revision.EditorId,
+		CreatedAt:   timestamppb.New(revision.CreatedAt),
+		Title:       revision.Title,
+		Description: revision.Description,
+		BlogTopic:   string(revision.BlogTopic),
+		Visibility:  string(revision.Visibility),
+	}
+}
+
+func (s *BlogMicroservice) SetLogLevel(ctx context.Context, req *LogLevelRequest) (*LogLevelResponse, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SetLogLevel")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("log.level", req.Level))
+
+	previous, err := s.LoggingService.SetLevel(ctx, req.Level)
+	if err != nil {
+		s.Logger.WarnContext(ctx, "Error setting log level", "error", err)
+		span.SetStatus(codes.Error, "SetLogLevel failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "SetLogLevel successful")
+	return &LogLevelResponse{
+		Level:         strings.ToLower(s.LoggingService.Level.Level().String()),
+		PreviousLevel: strings.ToLower(previous.String()),
+	}, nil
 }
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"BlogApplication/model"
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorInterceptor turns errors returned by handlers into gRPC statuses.
+// Domain errors keep their message and map to the matching code, with field
+// violations attached as a BadRequest detail. Errors that already carry a
+// status pass through, and running out of time or being cancelled map to
+// DeadlineExceeded and Canceled. Anything else is reported as Internal so
+// storage failures don't leak to clients.
+func ErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+	return resp, nil
+}
+
+func toStatusError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request cancelled")
+	}
+
+	var domainError *model.DomainError
+	if !errors.As(err, &domainError) {
+		slog.ErrorContext(ctx, "RPC failed", "error", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	st := status.New(grpcCodeOf(domainError.Kind), err.Error())
+	if len(domainError.Violations) == 0 {
+		return st.Err()
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range domainError.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Description,
+		})
+	}
+	detailed, detailErr := st.WithDetails(badRequest)
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func grpcCodeOf(kind model.ErrorKind) codes.Code {
+	switch kind {
+	case model.KindNotFound:
+		return codes.NotFound
+	case model.KindInvalidArgument:
+		return codes.InvalidArgument
+	case model.KindFailedPrecondition:
+		return codes.FailedPrecondition
+	case model.KindPermissionDenied:
+		return codes.PermissionDenied
+	case model.KindConflict:
+		return codes.AlreadyExists
+	case model.KindUnauthenticated:
+		return codes.Unauthenticated
+	default:
+		return codes.Unknown
+	}
+}
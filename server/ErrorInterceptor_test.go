@@ -0,0 +1,63 @@
+package server
+
+import (
+	"BlogApplication/model"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatusErrorCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{"status", status.Error(codes.Unavailable, "try later"), codes.Unavailable, "try later"},
+		{"not found", model.NotFound("blog with id %d not found", 1), codes.NotFound, "blog with id 1 not found"},
+		{"invalid argument", model.InvalidArgument("bad"), codes.InvalidArgument, "bad"},
+		{"failed precondition", model.FailedPrecondition("closed"), codes.FailedPrecondition, "closed"},
+		{"permission denied", model.PermissionDenied("no"), codes.PermissionDenied, "no"},
+		{"conflict", model.Conflict("taken"), codes.AlreadyExists, "taken"},
+		{"unauthenticated", model.Unauthenticated("who"), codes.Unauthenticated, "who"},
+		{"wrapped domain error", fmt.Errorf("saving: %w", model.NotFound("gone")), codes.NotFound, "saving: gone"},
+		{"deadline", fmt.Errorf("error fetching blog: %w", context.DeadlineExceeded), codes.DeadlineExceeded, "deadline exceeded"},
+		{"cancelled", fmt.Errorf("error fetching blog: %w", context.Canceled), codes.Canceled, "request cancelled"},
+		{"storage failure", errors.New("connection reset by mongo-1:27017"), codes.Internal, "internal error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st := status.Convert(toStatusError(context.Background(), test.err))
+			if st.Code() != test.code || st.Message() != test.message {
+				t.Errorf("got %s %q, want %s %q", st.Code(), st.Message(), test.code, test.message)
+			}
+		})
+	}
+}
+
+func TestToStatusErrorAttachesFieldViolations(t *testing.T) {
+	err := model.ValidationError([]model.FieldViolation{
+		{Field: "title", Description: "title is required"},
+		{Field: "page_token", Description: "invalid page token"},
+	})
+
+	st := status.Convert(toStatusError(context.Background(), err))
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %s, want InvalidArgument", st.Code())
+	}
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = append(violations, badRequest.FieldViolations...)
+		}
+	}
+	if len(violations) != 2 || violations[0].Field != "title" || violations[1].Field != "page_token" {
+		t.Errorf("violations = %v", violations)
+	}
+}
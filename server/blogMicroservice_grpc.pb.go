@@ -19,21 +19,34 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	BlogMicroservice_FindBlogById_FullMethodName       = "/server.BlogMicroservice/FindBlogById"
-	BlogMicroservice_CreateBlog_FullMethodName         = "/server.BlogMicroservice/CreateBlog"
-	BlogMicroservice_FindBlogsByType_FullMethodName    = "/server.BlogMicroservice/FindBlogsByType"
-	BlogMicroservice_FindPublishedBlogs_FullMethodName = "/server.BlogMicroservice/FindPublishedBlogs"
-	BlogMicroservice_FindBlogsByAuthor_FullMethodName  = "/server.BlogMicroservice/FindBlogsByAuthor"
-	BlogMicroservice_DeleteBlog_FullMethodName         = "/server.BlogMicroservice/DeleteBlog"
-	BlogMicroservice_BlockBlog_FullMethodName          = "/server.BlogMicroservice/BlockBlog"
-	BlogMicroservice_CreateComment_FullMethodName      = "/server.BlogMicroservice/CreateComment"
-	BlogMicroservice_UpdateComment_FullMethodName      = "/server.BlogMicroservice/UpdateComment"
-	BlogMicroservice_DeleteComment_FullMethodName      = "/server.BlogMicroservice/DeleteComment"
-	BlogMicroservice_GetAllComments_FullMethodName     = "/server.BlogMicroservice/GetAllComments"
-	BlogMicroservice_GetAllBlogComments_FullMethodName = "/server.BlogMicroservice/GetAllBlogComments"
-	BlogMicroservice_CreateReport_FullMethodName       = "/server.BlogMicroservice/CreateReport"
-	BlogMicroservice_FindReportsByBlog_FullMethodName  = "/server.BlogMicroservice/FindReportsByBlog"
-	BlogMicroservice_Vote_FullMethodName               = "/server.BlogMicroservice/Vote"
+	BlogMicroservice_FindBlogById_FullMethodName        = "/server.BlogMicroservice/FindBlogById"
+	BlogMicroservice_CreateBlog_FullMethodName          = "/server.BlogMicroservice/CreateBlog"
+	BlogMicroservice_UpdateBlog_FullMethodName          = "/server.BlogMicroservice/UpdateBlog"
+	BlogMicroservice_PublishBlog_FullMethodName         = "/server.BlogMicroservice/PublishBlog"
+	BlogMicroservice_UnpublishBlog_FullMethodName       = "/server.BlogMicroservice/UnpublishBlog"
+	BlogMicroservice_SchedulePublish_FullMethodName     = "/server.BlogMicroservice/SchedulePublish"
+	BlogMicroservice_ListBlogRevisions_FullMethodName   = "/server.BlogMicroservice/ListBlogRevisions"
+	BlogMicroservice_GetBlogRevision_FullMethodName     = "/server.BlogMicroservice/GetBlogRevision"
+	BlogMicroservice_RestoreBlogRevision_FullMethodName = "/server.BlogMicroservice/RestoreBlogRevision"
+	BlogMicroservice_DiffBlogRevisions_FullMethodName   = "/server.BlogMicroservice/DiffBlogRevisions"
+	BlogMicroservice_FindBlogsByType_FullMethodName     = "/server.BlogMicroservice/FindBlogsByType"
+	BlogMicroservice_FindPublishedBlogs_FullMethodName  = "/server.BlogMicroservice/FindPublishedBlogs"
+	BlogMicroservice_FindBlogsByAuthor_FullMethodName   = "/server.BlogMicroservice/FindBlogsByAuthor"
+	BlogMicroservice_DeleteBlog_FullMethodName          = "/server.BlogMicroservice/DeleteBlog"
+	BlogMicroservice_BlockBlog_FullMethodName           = "/server.BlogMicroservice/BlockBlog"
+	BlogMicroservice_CreateComment_FullMethodName       = "/server.BlogMicroservice/CreateComment"
+	BlogMicroservice_UpdateComment_FullMethodName       = "/server.BlogMicroservice/UpdateComment"
+	BlogMicroservice_DeleteComment_FullMethodName       = "/server.BlogMicroservice/DeleteComment"
+	BlogMicroservice_GetAllComments_FullMethodName      = "/server.BlogMicroservice/GetAllComments"
+	BlogMicroservice_GetAllBlogComments_FullMethodName  = "/server.BlogMicroservice/GetAllBlogComments"
+	BlogMicroservice_GetCommentThread_FullMethodName    = "/server.BlogMicroservice/GetCommentThread"
+	BlogMicroservice_CreateReport_FullMethodName        = "/server.BlogMicroservice/CreateReport"
+	BlogMicroservice_FindReportsByBlog_FullMethodName   = "/server.BlogMicroservice/FindReportsByBlog"
+	BlogMicroservice_Vote_FullMethodName                = "/server.BlogMicroservice/Vote"
+	BlogMicroservice_RemoveVote_FullMethodName          = "/server.BlogMicroservice/RemoveVote"
+	BlogMicroservice_ListDeadLetters_FullMethodName     = "/server.BlogMicroservice/ListDeadLetters"
+	BlogMicroservice_ReplayDeadLetter_FullMethodName    = "/server.BlogMicroservice/ReplayDeadLetter"
+	BlogMicroservice_SetLogLevel_FullMethodName         = "/server.BlogMicroservice/SetLogLevel"
 )
 
 // BlogMicroserviceClient is the client API for BlogMicroservice service.
@@ -42,19 +55,32 @@ const (
 type BlogMicroserviceClient interface {
 	FindBlogById(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*BlogResponse, error)
 	CreateBlog(ctx context.Context, in *BlogCreationRequest, opts ...grpc.CallOption) (*StringMessage, error)
+	UpdateBlog(ctx context.Context, in *BlogUpdateRequest, opts ...grpc.CallOption) (*BlogResponse, error)
+	PublishBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*BlogResponse, error)
+	UnpublishBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*BlogResponse, error)
+	SchedulePublish(ctx context.Context, in *SchedulePublishRequest, opts ...grpc.CallOption) (*BlogResponse, error)
+	ListBlogRevisions(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*BlogRevisionListResponse, error)
+	GetBlogRevision(ctx context.Context, in *BlogRevisionRequest, opts ...grpc.CallOption) (*BlogRevisionResponse, error)
+	RestoreBlogRevision(ctx context.Context, in *RestoreBlogRevisionRequest, opts ...grpc.CallOption) (*BlogResponse, error)
+	DiffBlogRevisions(ctx context.Context, in *BlogRevisionDiffRequest, opts ...grpc.CallOption) (*BlogRevisionDiffResponse, error)
 	FindBlogsByType(ctx context.Context, in *TypeRequest, opts ...grpc.CallOption) (*BlogListResponse, error)
-	FindPublishedBlogs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BlogListResponse, error)
+	FindPublishedBlogs(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*BlogListResponse, error)
 	FindBlogsByAuthor(ctx context.Context, in *AuthorIdRequest, opts ...grpc.CallOption) (*BlogListResponse, error)
 	DeleteBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*StringMessage, error)
 	BlockBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*StringMessage, error)
 	CreateComment(ctx context.Context, in *CommentCreationRequest, opts ...grpc.CallOption) (*CommentResponse, error)
 	UpdateComment(ctx context.Context, in *CommentUpdateRequest, opts ...grpc.CallOption) (*StringMessage, error)
 	DeleteComment(ctx context.Context, in *CommentIdRequest, opts ...grpc.CallOption) (*StringMessage, error)
-	GetAllComments(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommentListResponse, error)
-	GetAllBlogComments(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*CommentListResponse, error)
+	GetAllComments(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*CommentListResponse, error)
+	GetAllBlogComments(ctx context.Context, in *BlogPageRequest, opts ...grpc.CallOption) (*CommentListResponse, error)
+	GetCommentThread(ctx context.Context, in *CommentThreadRequest, opts ...grpc.CallOption) (*CommentThreadResponse, error)
 	CreateReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*StringMessage, error)
-	FindReportsByBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*ReportListResponse, error)
+	FindReportsByBlog(ctx context.Context, in *BlogPageRequest, opts ...grpc.CallOption) (*ReportListResponse, error)
 	Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*StringMessage, error)
+	RemoveVote(ctx context.Context, in *RemoveVoteRequest, opts ...grpc.CallOption) (*BlogResponse, error)
+	ListDeadLetters(ctx context.Context, in *DeadLetterListRequest, opts ...grpc.CallOption) (*DeadLetterListResponse, error)
+	ReplayDeadLetter(ctx context.Context, in *DeadLetterRequest, opts ...grpc.CallOption) (*StringMessage, error)
+	SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
 }
 
 type blogMicroserviceClient struct {
@@ -83,6 +109,78 @@ func (c *blogMicroserviceClient) CreateBlog(ctx context.Context, in *BlogCreatio
 	return out, nil
 }
 
+func (c *blogMicroserviceClient) UpdateBlog(ctx context.Context, in *BlogUpdateRequest, opts ...grpc.CallOption) (*BlogResponse, error) {
+	out := new(BlogResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_UpdateBlog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) PublishBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*BlogResponse, error) {
+	out := new(BlogResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_PublishBlog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) UnpublishBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*BlogResponse, error) {
+	out := new(BlogResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_UnpublishBlog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) SchedulePublish(ctx context.Context, in *SchedulePublishRequest, opts ...grpc.CallOption) (*BlogResponse, error) {
+	out := new(BlogResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_SchedulePublish_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) ListBlogRevisions(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*BlogRevisionListResponse, error) {
+	out := new(BlogRevisionListResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_ListBlogRevisions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) GetBlogRevision(ctx context.Context, in *BlogRevisionRequest, opts ...grpc.CallOption) (*BlogRevisionResponse, error) {
+	out := new(BlogRevisionResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_GetBlogRevision_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) RestoreBlogRevision(ctx context.Context, in *RestoreBlogRevisionRequest, opts ...grpc.CallOption) (*BlogResponse, error) {
+	out := new(BlogResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_RestoreBlogRevision_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) DiffBlogRevisions(ctx context.Context, in *BlogRevisionDiffRequest, opts ...grpc.CallOption) (*BlogRevisionDiffResponse, error) {
+	out := new(BlogRevisionDiffResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_DiffBlogRevisions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *blogMicroserviceClient) FindBlogsByType(ctx context.Context, in *TypeRequest, opts ...grpc.CallOption) (*BlogListResponse, error) {
 	out := new(BlogListResponse)
 	err := c.cc.Invoke(ctx, BlogMicroservice_FindBlogsByType_FullMethodName, in, out, opts...)
@@ -92,7 +190,7 @@ func (c *blogMicroserviceClient) FindBlogsByType(ctx context.Context, in *TypeRe
 	return out, nil
 }
 
-func (c *blogMicroserviceClient) FindPublishedBlogs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BlogListResponse, error) {
+func (c *blogMicroserviceClient) FindPublishedBlogs(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*BlogListResponse, error) {
 	out := new(BlogListResponse)
 	err := c.cc.Invoke(ctx, BlogMicroservice_FindPublishedBlogs_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -155,7 +253,7 @@ func (c *blogMicroserviceClient) DeleteComment(ctx context.Context, in *CommentI
 	return out, nil
 }
 
-func (c *blogMicroserviceClient) GetAllComments(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommentListResponse, error) {
+func (c *blogMicroserviceClient) GetAllComments(ctx context.Context, in *PageRequest, opts ...grpc.CallOption) (*CommentListResponse, error) {
 	out := new(CommentListResponse)
 	err := c.cc.Invoke(ctx, BlogMicroservice_GetAllComments_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -164,7 +262,7 @@ func (c *blogMicroserviceClient) GetAllComments(ctx context.Context, in *Empty,
 	return out, nil
 }
 
-func (c *blogMicroserviceClient) GetAllBlogComments(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*CommentListResponse, error) {
+func (c *blogMicroserviceClient) GetAllBlogComments(ctx context.Context, in *BlogPageRequest, opts ...grpc.CallOption) (*CommentListResponse, error) {
 	out := new(CommentListResponse)
 	err := c.cc.Invoke(ctx, BlogMicroservice_GetAllBlogComments_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -173,6 +271,15 @@ func (c *blogMicroserviceClient) GetAllBlogComments(ctx context.Context, in *Blo
 	return out, nil
 }
 
+func (c *blogMicroserviceClient) GetCommentThread(ctx context.Context, in *CommentThreadRequest, opts ...grpc.CallOption) (*CommentThreadResponse, error) {
+	out := new(CommentThreadResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_GetCommentThread_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *blogMicroserviceClient) CreateReport(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*StringMessage, error) {
 	out := new(StringMessage)
 	err := c.cc.Invoke(ctx, BlogMicroservice_CreateReport_FullMethodName, in, out, opts...)
@@ -182,7 +289,7 @@ func (c *blogMicroserviceClient) CreateReport(ctx context.Context, in *ReportReq
 	return out, nil
 }
 
-func (c *blogMicroserviceClient) FindReportsByBlog(ctx context.Context, in *BlogIdRequest, opts ...grpc.CallOption) (*ReportListResponse, error) {
+func (c *blogMicroserviceClient) FindReportsByBlog(ctx context.Context, in *BlogPageRequest, opts ...grpc.CallOption) (*ReportListResponse, error) {
 	out := new(ReportListResponse)
 	err := c.cc.Invoke(ctx, BlogMicroservice_FindReportsByBlog_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -200,25 +307,74 @@ func (c *blogMicroserviceClient) Vote(ctx context.Context, in *VoteRequest, opts
 	return out, nil
 }
 
+func (c *blogMicroserviceClient) RemoveVote(ctx context.Context, in *RemoveVoteRequest, opts ...grpc.CallOption) (*BlogResponse, error) {
+	out := new(BlogResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_RemoveVote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) ListDeadLetters(ctx context.Context, in *DeadLetterListRequest, opts ...grpc.CallOption) (*DeadLetterListResponse, error) {
+	out := new(DeadLetterListResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_ListDeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) ReplayDeadLetter(ctx context.Context, in *DeadLetterRequest, opts ...grpc.CallOption) (*StringMessage, error) {
+	out := new(StringMessage)
+	err := c.cc.Invoke(ctx, BlogMicroservice_ReplayDeadLetter_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogMicroserviceClient) SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error) {
+	out := new(LogLevelResponse)
+	err := c.cc.Invoke(ctx, BlogMicroservice_SetLogLevel_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BlogMicroserviceServer is the server API for BlogMicroservice service.
 // All implementations must embed UnimplementedBlogMicroserviceServer
 // for forward compatibility
 type BlogMicroserviceServer interface {
 	FindBlogById(context.Context, *BlogIdRequest) (*BlogResponse, error)
 	CreateBlog(context.Context, *BlogCreationRequest) (*StringMessage, error)
+	UpdateBlog(context.Context, *BlogUpdateRequest) (*BlogResponse, error)
+	PublishBlog(context.Context, *BlogIdRequest) (*BlogResponse, error)
+	UnpublishBlog(context.Context, *BlogIdRequest) (*BlogResponse, error)
+	SchedulePublish(context.Context, *SchedulePublishRequest) (*BlogResponse, error)
+	ListBlogRevisions(context.Context, *BlogIdRequest) (*BlogRevisionListResponse, error)
+	GetBlogRevision(context.Context, *BlogRevisionRequest) (*BlogRevisionResponse, error)
+	RestoreBlogRevision(context.Context, *RestoreBlogRevisionRequest) (*BlogResponse, error)
+	DiffBlogRevisions(context.Context, *BlogRevisionDiffRequest) (*BlogRevisionDiffResponse, error)
 	FindBlogsByType(context.Context, *TypeRequest) (*BlogListResponse, error)
-	FindPublishedBlogs(context.Context, *Empty) (*BlogListResponse, error)
+	FindPublishedBlogs(context.Context, *PageRequest) (*BlogListResponse, error)
 	FindBlogsByAuthor(context.Context, *AuthorIdRequest) (*BlogListResponse, error)
 	DeleteBlog(context.Context, *BlogIdRequest) (*StringMessage, error)
 	BlockBlog(context.Context, *BlogIdRequest) (*StringMessage, error)
 	CreateComment(context.Context, *CommentCreationRequest) (*CommentResponse, error)
 	UpdateComment(context.Context, *CommentUpdateRequest) (*StringMessage, error)
 	DeleteComment(context.Context, *CommentIdRequest) (*StringMessage, error)
-	GetAllComments(context.Context, *Empty) (*CommentListResponse, error)
-	GetAllBlogComments(context.Context, *BlogIdRequest) (*CommentListResponse, error)
+	GetAllComments(context.Context, *PageRequest) (*CommentListResponse, error)
+	GetAllBlogComments(context.Context, *BlogPageRequest) (*CommentListResponse, error)
+	GetCommentThread(context.Context, *CommentThreadRequest) (*CommentThreadResponse, error)
 	CreateReport(context.Context, *ReportRequest) (*StringMessage, error)
-	FindReportsByBlog(context.Context, *BlogIdRequest) (*ReportListResponse, error)
+	FindReportsByBlog(context.Context, *BlogPageRequest) (*ReportListResponse, error)
 	Vote(context.Context, *VoteRequest) (*StringMessage, error)
+	RemoveVote(context.Context, *RemoveVoteRequest) (*BlogResponse, error)
+	ListDeadLetters(context.Context, *DeadLetterListRequest) (*DeadLetterListResponse, error)
+	ReplayDeadLetter(context.Context, *DeadLetterRequest) (*StringMessage, error)
+	SetLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
 	mustEmbedUnimplementedBlogMicroserviceServer()
 }
 
@@ -232,10 +388,34 @@ func (UnimplementedBlogMicroserviceServer) FindBlogById(context.Context, *BlogId
 func (UnimplementedBlogMicroserviceServer) CreateBlog(context.Context, *BlogCreationRequest) (*StringMessage, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateBlog not implemented")
 }
+func (UnimplementedBlogMicroserviceServer) UpdateBlog(context.Context, *BlogUpdateRequest) (*BlogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBlog not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) PublishBlog(context.Context, *BlogIdRequest) (*BlogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishBlog not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) UnpublishBlog(context.Context, *BlogIdRequest) (*BlogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnpublishBlog not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) SchedulePublish(context.Context, *SchedulePublishRequest) (*BlogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SchedulePublish not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) ListBlogRevisions(context.Context, *BlogIdRequest) (*BlogRevisionListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlogRevisions not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) GetBlogRevision(context.Context, *BlogRevisionRequest) (*BlogRevisionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlogRevision not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) RestoreBlogRevision(context.Context, *RestoreBlogRevisionRequest) (*BlogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreBlogRevision not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) DiffBlogRevisions(context.Context, *BlogRevisionDiffRequest) (*BlogRevisionDiffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffBlogRevisions not implemented")
+}
 func (UnimplementedBlogMicroserviceServer) FindBlogsByType(context.Context, *TypeRequest) (*BlogListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindBlogsByType not implemented")
 }
-func (UnimplementedBlogMicroserviceServer) FindPublishedBlogs(context.Context, *Empty) (*BlogListResponse, error) {
+func (UnimplementedBlogMicroserviceServer) FindPublishedBlogs(context.Context, *PageRequest) (*BlogListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindPublishedBlogs not implemented")
 }
 func (UnimplementedBlogMicroserviceServer) FindBlogsByAuthor(context.Context, *AuthorIdRequest) (*BlogListResponse, error) {
@@ -256,21 +436,36 @@ func (UnimplementedBlogMicroserviceServer) UpdateComment(context.Context, *Comme
 func (UnimplementedBlogMicroserviceServer) DeleteComment(context.Context, *CommentIdRequest) (*StringMessage, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteComment not implemented")
 }
-func (UnimplementedBlogMicroserviceServer) GetAllComments(context.Context, *Empty) (*CommentListResponse, error) {
+func (UnimplementedBlogMicroserviceServer) GetAllComments(context.Context, *PageRequest) (*CommentListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllComments not implemented")
 }
-func (UnimplementedBlogMicroserviceServer) GetAllBlogComments(context.Context, *BlogIdRequest) (*CommentListResponse, error) {
+func (UnimplementedBlogMicroserviceServer) GetAllBlogComments(context.Context, *BlogPageRequest) (*CommentListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllBlogComments not implemented")
 }
+func (UnimplementedBlogMicroserviceServer) GetCommentThread(context.Context, *CommentThreadRequest) (*CommentThreadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommentThread not implemented")
+}
 func (UnimplementedBlogMicroserviceServer) CreateReport(context.Context, *ReportRequest) (*StringMessage, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateReport not implemented")
 }
-func (UnimplementedBlogMicroserviceServer) FindReportsByBlog(context.Context, *BlogIdRequest) (*ReportListResponse, error) {
+func (UnimplementedBlogMicroserviceServer) FindReportsByBlog(context.Context, *BlogPageRequest) (*ReportListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindReportsByBlog not implemented")
 }
 func (UnimplementedBlogMicroserviceServer) Vote(context.Context, *VoteRequest) (*StringMessage, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Vote not implemented")
 }
+func (UnimplementedBlogMicroserviceServer) RemoveVote(context.Context, *RemoveVoteRequest) (*BlogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveVote not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) ListDeadLetters(context.Context, *DeadLetterListRequest) (*DeadLetterListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeadLetters not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) ReplayDeadLetter(context.Context, *DeadLetterRequest) (*StringMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayDeadLetter not implemented")
+}
+func (UnimplementedBlogMicroserviceServer) SetLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
 func (UnimplementedBlogMicroserviceServer) mustEmbedUnimplementedBlogMicroserviceServer() {}
 
 // UnsafeBlogMicroserviceServer may be embedded to opt out of forward compatibility for this service.
@@ -320,6 +515,150 @@ func _BlogMicroservice_CreateBlog_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlogMicroservice_UpdateBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlogUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).UpdateBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_UpdateBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).UpdateBlog(ctx, req.(*BlogUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_PublishBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlogIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).PublishBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_PublishBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).PublishBlog(ctx, req.(*BlogIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_UnpublishBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlogIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).UnpublishBlog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_UnpublishBlog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).UnpublishBlog(ctx, req.(*BlogIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_SchedulePublish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SchedulePublishRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).SchedulePublish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_SchedulePublish_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).SchedulePublish(ctx, req.(*SchedulePublishRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_ListBlogRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlogIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).ListBlogRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_ListBlogRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).ListBlogRevisions(ctx, req.(*BlogIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_GetBlogRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlogRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).GetBlogRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_GetBlogRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).GetBlogRevision(ctx, req.(*BlogRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_RestoreBlogRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreBlogRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).RestoreBlogRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_RestoreBlogRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).RestoreBlogRevision(ctx, req.(*RestoreBlogRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_DiffBlogRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlogRevisionDiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).DiffBlogRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_DiffBlogRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).DiffBlogRevisions(ctx, req.(*BlogRevisionDiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BlogMicroservice_FindBlogsByType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TypeRequest)
 	if err := dec(in); err != nil {
@@ -339,7 +678,7 @@ func _BlogMicroservice_FindBlogsByType_Handler(srv interface{}, ctx context.Cont
 }
 
 func _BlogMicroservice_FindPublishedBlogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(PageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -351,7 +690,7 @@ func _BlogMicroservice_FindPublishedBlogs_Handler(srv interface{}, ctx context.C
 		FullMethod: BlogMicroservice_FindPublishedBlogs_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BlogMicroserviceServer).FindPublishedBlogs(ctx, req.(*Empty))
+		return srv.(BlogMicroserviceServer).FindPublishedBlogs(ctx, req.(*PageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -465,7 +804,7 @@ func _BlogMicroservice_DeleteComment_Handler(srv interface{}, ctx context.Contex
 }
 
 func _BlogMicroservice_GetAllComments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(PageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -477,13 +816,13 @@ func _BlogMicroservice_GetAllComments_Handler(srv interface{}, ctx context.Conte
 		FullMethod: BlogMicroservice_GetAllComments_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BlogMicroserviceServer).GetAllComments(ctx, req.(*Empty))
+		return srv.(BlogMicroserviceServer).GetAllComments(ctx, req.(*PageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
 func _BlogMicroservice_GetAllBlogComments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BlogIdRequest)
+	in := new(BlogPageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -495,7 +834,25 @@ func _BlogMicroservice_GetAllBlogComments_Handler(srv interface{}, ctx context.C
 		FullMethod: BlogMicroservice_GetAllBlogComments_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BlogMicroserviceServer).GetAllBlogComments(ctx, req.(*BlogIdRequest))
+		return srv.(BlogMicroserviceServer).GetAllBlogComments(ctx, req.(*BlogPageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_GetCommentThread_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommentThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).GetCommentThread(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_GetCommentThread_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).GetCommentThread(ctx, req.(*CommentThreadRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -519,7 +876,7 @@ func _BlogMicroservice_CreateReport_Handler(srv interface{}, ctx context.Context
 }
 
 func _BlogMicroservice_FindReportsByBlog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BlogIdRequest)
+	in := new(BlogPageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -531,7 +888,7 @@ func _BlogMicroservice_FindReportsByBlog_Handler(srv interface{}, ctx context.Co
 		FullMethod: BlogMicroservice_FindReportsByBlog_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(BlogMicroserviceServer).FindReportsByBlog(ctx, req.(*BlogIdRequest))
+		return srv.(BlogMicroserviceServer).FindReportsByBlog(ctx, req.(*BlogPageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -554,6 +911,78 @@ func _BlogMicroservice_Vote_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlogMicroservice_RemoveVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).RemoveVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_RemoveVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).RemoveVote(ctx, req.(*RemoveVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_ListDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).ListDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_ListDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).ListDeadLetters(ctx, req.(*DeadLetterListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_ReplayDeadLetter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).ReplayDeadLetter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_ReplayDeadLetter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).ReplayDeadLetter(ctx, req.(*DeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogMicroservice_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogMicroserviceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogMicroservice_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogMicroserviceServer).SetLogLevel(ctx, req.(*LogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BlogMicroservice_ServiceDesc is the grpc.ServiceDesc for BlogMicroservice service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -569,6 +998,38 @@ var BlogMicroservice_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateBlog",
 			Handler:    _BlogMicroservice_CreateBlog_Handler,
 		},
+		{
+			MethodName: "UpdateBlog",
+			Handler:    _BlogMicroservice_UpdateBlog_Handler,
+		},
+		{
+			MethodName: "PublishBlog",
+			Handler:    _BlogMicroservice_PublishBlog_Handler,
+		},
+		{
+			MethodName: "UnpublishBlog",
+			Handler:    _BlogMicroservice_UnpublishBlog_Handler,
+		},
+		{
+			MethodName: "SchedulePublish",
+			Handler:    _BlogMicroservice_SchedulePublish_Handler,
+		},
+		{
+			MethodName: "ListBlogRevisions",
+			Handler:    _BlogMicroservice_ListBlogRevisions_Handler,
+		},
+		{
+			MethodName: "GetBlogRevision",
+			Handler:    _BlogMicroservice_GetBlogRevision_Handler,
+		},
+		{
+			MethodName: "RestoreBlogRevision",
+			Handler:    _BlogMicroservice_RestoreBlogRevision_Handler,
+		},
+		{
+			MethodName: "DiffBlogRevisions",
+			Handler:    _BlogMicroservice_DiffBlogRevisions_Handler,
+		},
 		{
 			MethodName: "FindBlogsByType",
 			Handler:    _BlogMicroservice_FindBlogsByType_Handler,
@@ -609,6 +1070,10 @@ var BlogMicroservice_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllBlogComments",
 			Handler:    _BlogMicroservice_GetAllBlogComments_Handler,
 		},
+		{
+			MethodName: "GetCommentThread",
+			Handler:    _BlogMicroservice_GetCommentThread_Handler,
+		},
 		{
 			MethodName: "CreateReport",
 			Handler:    _BlogMicroservice_CreateReport_Handler,
@@ -621,6 +1086,22 @@ var BlogMicroservice_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Vote",
 			Handler:    _BlogMicroservice_Vote_Handler,
 		},
+		{
+			MethodName: "RemoveVote",
+			Handler:    _BlogMicroservice_RemoveVote_Handler,
+		},
+		{
+			MethodName: "ListDeadLetters",
+			Handler:    _BlogMicroservice_ListDeadLetters_Handler,
+		},
+		{
+			MethodName: "ReplayDeadLetter",
+			Handler:    _BlogMicroservice_ReplayDeadLetter_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _BlogMicroservice_SetLogLevel_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "blogMicroservice.proto",
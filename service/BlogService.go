@@ -1,19 +1,37 @@
 package service
 
 import (
+	"BlogApplication/auth"
+	"BlogApplication/events"
 	"BlogApplication/model"
 	"BlogApplication/repository"
+	"BlogApplication/telemetry"
+	"BlogApplication/useCases"
 	"context"
-	"encoding/json"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
+// Blog fields that can be targeted by a partial update.
+const (
+	BlogFieldTitle       = "title"
+	BlogFieldDescription = "description"
+	BlogFieldTopic       = "blog_topic"
+	BlogFieldVisibility  = "visibility"
+)
+
 type BlogService struct {
-	BlogRepository *repository.BlogRepository
+	BlogRepository     *repository.BlogRepository
+	RevisionRepository *repository.BlogRevisionRepository
+	VoteRepository     *repository.VoteRepository
+	Outbox             *Outbox
+	Policy             *Policy
+	Logger             *slog.Logger
 }
 
 func (service *BlogService) Find(ctx context.Context, id int64) (*model.Blog, error) {
@@ -21,42 +39,84 @@ func (service *BlogService) Find(ctx context.Context, id int64) (*model.Blog, er
 	ctx, span := tracer.Start(ctx, "Find")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(id))
 
 	blog, err := service.BlogRepository.Find(ctx, id)
 	if err != nil {
 		span.SetStatus(codes.Error, "Find failed")
-		return nil, fmt.Errorf(fmt.Sprintf("menu item with id %d not found", id))
+		return nil, err
 	}
 
 	span.SetStatus(codes.Ok, "Find successful")
 	return &blog, nil
 }
 
-func (service *BlogService) FindAllPublished(ctx context.Context) ([]model.Blog, error) {
+// FindVisible finds a blog the caller in ctx may see. A blog hidden from
+// the caller is reported as not found, so its existence doesn't leak.
+func (service *BlogService) FindVisible(ctx context.Context, id int64) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "FindVisible")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(id))
+
+	viewer, err := service.Policy.Viewer(ctx, false)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindVisible failed")
+		return nil, err
+	}
+	blog, err := service.BlogRepository.Find(ctx, id)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindVisible failed")
+		return nil, err
+	}
+	if !blog.IsVisibleTo(viewer) {
+		span.SetStatus(codes.Error, "FindVisible failed")
+		return nil, model.NotFound("blog with id %d not found", id)
+	}
+
+	span.SetStatus(codes.Ok, "FindVisible successful")
+	return &blog, nil
+}
+
+// Viewer describes the caller in ctx for blog listings. includeHidden is
+// only honoured for administrators.
+func (service *BlogService) Viewer(ctx context.Context, includeHidden bool) (model.BlogViewer, error) {
+	return service.Policy.Viewer(ctx, includeHidden)
+}
+
+func (service *BlogService) FindAllPublishedPage(ctx context.Context, viewer model.BlogViewer, page useCases.PageRequest) (*useCases.PagedResult[model.Blog], error) {
 	tracer := otel.Tracer("service")
-	ctx, span := tracer.Start(ctx, "FindAllPublished")
+	ctx, span := tracer.Start(ctx, "FindAllPublishedPage")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{}"))
+	span.SetAttributes(telemetry.PageSize(page.Limit()))
 
-	blogs, _ := service.BlogRepository.FindAllPublished(ctx)
+	result, err := service.BlogRepository.FindAllPublishedPage(ctx, viewer, page)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindAllPublishedPage failed")
+		return nil, err
+	}
 
-	span.SetStatus(codes.Ok, "FindAllPublished successful")
-	return blogs, nil
+	span.SetStatus(codes.Ok, "FindAllPublishedPage successful")
+	return result, nil
 }
 
-func (service *BlogService) FindAllByAuthor(ctx context.Context, id int64) ([]model.Blog, error) {
+func (service *BlogService) FindAllByAuthorPage(ctx context.Context, id int64, viewer model.BlogViewer, page useCases.PageRequest) (*useCases.PagedResult[model.Blog], error) {
 	tracer := otel.Tracer("service")
-	ctx, span := tracer.Start(ctx, "FindAllByAuthor")
+	ctx, span := tracer.Start(ctx, "FindAllByAuthorPage")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.UserId(id))
 
-	blogs, _ := service.BlogRepository.FindAllByAuthor(ctx, id)
+	result, err := service.BlogRepository.FindAllByAuthorPage(ctx, id, viewer, page)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindAllByAuthorPage failed")
+		return nil, err
+	}
 
-	span.SetStatus(codes.Ok, "FindAllByAuthor successful")
-	return blogs, nil
+	span.SetStatus(codes.Ok, "FindAllByAuthorPage successful")
+	return result, nil
 }
 
 func (service *BlogService) Create(ctx context.Context, blog *model.Blog) error {
@@ -64,70 +124,145 @@ func (service *BlogService) Create(ctx context.Context, blog *model.Blog) error
 	ctx, span := tracer.Start(ctx, "Create")
 	defer span.End()
 
-	reqData, err := json.Marshal(blog)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return err
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.UserId(blog.AuthorId), telemetry.Topic(string(blog.BlogTopic)), telemetry.Text("blog.title", blog.Title))
 
 	blog.DownvoteCount = 0
 	blog.UpvoteCount = 0
 	blog.VoteCount = 0
-	blog.Status = "published"
+	if blog.Status == "" {
+		blog.Status = model.Published
+	}
+	if blog.Status != model.Published && blog.Status != model.Draft {
+		span.SetStatus(codes.Error, "Create failed")
+		return model.InvalidField("status", "blogs can only be created as %s or %s", model.Published, model.Draft)
+	}
 	blog.Visibility = "public"
 	blog.Votes = []model.Vote{}
 	blog.Comments = []model.Comment{}
-	err = blog.Validate()
+	err := blog.Validate()
 	if err != nil {
 		span.SetStatus(codes.Error, "Create failed")
 		return err
 	}
-	err = service.BlogRepository.Create(ctx, blog)
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		if err := service.BlogRepository.Create(ctx, blog); err != nil {
+			return err
+		}
+		if err := service.RevisionRepository.Create(ctx, model.NewBlogRevision(blog, blog.AuthorId, blog.Date)); err != nil {
+			return fmt.Errorf("error recording blog revision: %w", err)
+		}
+		return service.Outbox.Enqueue(ctx, events.BlogCreated{
+			BlogId:    int64(blog.Id),
+			AuthorId:  blog.AuthorId,
+			Title:     blog.Title,
+			BlogTopic: string(blog.BlogTopic),
+			Status:    string(blog.Status),
+			CreatedAt: blog.Date,
+		})
+	})
 	if err != nil {
 		span.SetStatus(codes.Error, "Create failed")
 		return err
 	}
-	span.SetStatus(codes.Ok, "Create successful")
 
+	service.Logger.InfoContext(ctx, "Blog created", "blog.id", blog.Id, "blog.status", string(blog.Status))
 	span.SetStatus(codes.Ok, "Create successful")
 	return nil
 }
 
-func (service *BlogService) Update(ctx context.Context, id int64, blog *model.Blog) error {
+func (service *BlogService) Update(ctx context.Context, id int64, editorId int64, blog *model.Blog, fields []string) (*model.Blog, error) {
 	tracer := otel.Tracer("service")
 	ctx, span := tracer.Start(ctx, "Update")
 	defer span.End()
 
-	reqData, err := json.Marshal(blog)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(id), telemetry.Text("blog.title", blog.Title))
 
 	oldBlog, err := service.BlogRepository.Find(ctx, id)
 	if err != nil {
 		span.SetStatus(codes.Error, "Update failed")
-		return fmt.Errorf(fmt.Sprintf("menu item with id %d not found", id))
+		return nil, err
 	}
-	oldBlog.Title = blog.Title
-	oldBlog.Description = blog.Description
-	err = oldBlog.Validate()
+	err = service.Policy.CanEditBlog(ctx, &oldBlog)
 	if err != nil {
 		span.SetStatus(codes.Error, "Update failed")
-		return err
+		return nil, err
 	}
-	err = service.BlogRepository.Update(ctx, &oldBlog)
+	if oldBlog.Status == model.Closed {
+		span.SetStatus(codes.Error, "Update failed")
+		return nil, model.FailedPrecondition("blog with id %d is closed and can't be edited", id)
+	}
+
+	fields, err = service.applyUpdate(ctx, &oldBlog, blog, fields)
 	if err != nil {
 		span.SetStatus(codes.Error, "Update failed")
-		return err
+		return nil, err
+	}
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		if err := service.BlogRepository.Update(ctx, &oldBlog); err != nil {
+			return err
+		}
+		if err := service.RevisionRepository.Create(ctx, model.NewBlogRevision(&oldBlog, editorId, time.Now())); err != nil {
+			return fmt.Errorf("error recording blog revision: %w", err)
+		}
+		return service.Outbox.Enqueue(ctx, events.BlogUpdated{BlogId: id, EditorId: editorId, Fields: fields})
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "Update failed")
+		return nil, err
 	}
 
+	service.Logger.InfoContext(ctx, "Blog updated", "blog.id", id, "user.id", editorId, "fields", fields)
 	span.SetStatus(codes.Ok, "Update successful")
-	return nil
+	return &oldBlog, nil
+}
+
+// applyUpdate applies an edit to blog, checks the caller may make it and
+// validates the result. It returns the fields it updated.
+func (service *BlogService) applyUpdate(ctx context.Context, blog *model.Blog, patch *model.Blog, fields []string) ([]string, error) {
+	visibility := blog.Visibility
+	fields, err := applyBlogFields(blog, patch, fields)
+	if err != nil {
+		return nil, err
+	}
+	// Visibility is how moderators block a blog, so only they may change it;
+	// otherwise an author could unblock their own blog.
+	if blog.Visibility != visibility {
+		if err := service.Policy.CanBlockBlog(ctx, blog); err != nil {
+			return nil, err
+		}
+	}
+	if err := blog.Validate(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// applyBlogFields copies the fields named in the mask from patch onto blog
+// and returns the fields it updated. An empty mask updates the content
+// fields, and visibility only if patch sets it, so a plain edit doesn't
+// count as unblocking or hiding the blog.
+func applyBlogFields(blog *model.Blog, patch *model.Blog, fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		fields = []string{BlogFieldTitle, BlogFieldDescription, BlogFieldTopic}
+		if patch.Visibility != "" {
+			fields = append(fields, BlogFieldVisibility)
+		}
+	}
+	for _, field := range fields {
+		switch field {
+		case BlogFieldTitle:
+			blog.Title = patch.Title
+		case BlogFieldDescription:
+			blog.Description = patch.Description
+		case BlogFieldTopic:
+			blog.BlogTopic = patch.BlogTopic
+		case BlogFieldVisibility:
+			blog.Visibility = patch.Visibility
+		default:
+			return nil, model.InvalidField("update_mask", "field %q can't be updated", field)
+		}
+	}
+	return fields, nil
 }
 
 func (service *BlogService) Block(ctx context.Context, id int64) error {
@@ -135,12 +270,17 @@ func (service *BlogService) Block(ctx context.Context, id int64) error {
 	ctx, span := tracer.Start(ctx, "Block")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(id))
 
 	oldBlog, err := service.BlogRepository.Find(ctx, id)
 	if err != nil {
 		span.SetStatus(codes.Error, "Block failed")
-		return fmt.Errorf(fmt.Sprintf("menu item with id %d not found", id))
+		return err
+	}
+	err = service.Policy.CanBlockBlog(ctx, &oldBlog)
+	if err != nil {
+		span.SetStatus(codes.Error, "Block failed")
+		return err
 	}
 	oldBlog.Visibility = "private"
 	err = oldBlog.Validate()
@@ -148,12 +288,27 @@ func (service *BlogService) Block(ctx context.Context, id int64) error {
 		span.SetStatus(codes.Error, "Block failed")
 		return err
 	}
-	err = service.BlogRepository.Update(ctx, &oldBlog)
+	// The block is recorded as a revision, so the history shows who hid the
+	// blog and when.
+	var moderatorId int64
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		moderatorId = principal.UserId
+	}
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		if err := service.BlogRepository.Update(ctx, &oldBlog); err != nil {
+			return err
+		}
+		if err := service.RevisionRepository.Create(ctx, model.NewBlogRevision(&oldBlog, moderatorId, time.Now())); err != nil {
+			return fmt.Errorf("error recording blog revision: %w", err)
+		}
+		return service.Outbox.Enqueue(ctx, events.BlogBlocked{BlogId: id, AuthorId: oldBlog.AuthorId})
+	})
 	if err != nil {
 		span.SetStatus(codes.Error, "Block failed")
 		return err
 	}
 
+	service.Logger.InfoContext(ctx, "Blog blocked", "blog.id", id)
 	span.SetStatus(codes.Ok, "Block successful")
 	return nil
 }
@@ -163,55 +318,404 @@ func (service *BlogService) Delete(ctx context.Context, id int64) error {
 	ctx, span := tracer.Start(ctx, "Delete")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(id))
+
+	blog, err := service.BlogRepository.Find(ctx, id)
+	if err != nil {
+		span.SetStatus(codes.Error, "Delete failed")
+		return err
+	}
+	err = service.Policy.CanDeleteBlog(ctx, &blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "Delete failed")
+		return err
+	}
 
-	err := service.BlogRepository.Delete(ctx, id)
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		if err := service.BlogRepository.Delete(ctx, id); err != nil {
+			return fmt.Errorf("error deleting blog: %w", err)
+		}
+		if _, err := service.VoteRepository.DeleteAllByBlog(ctx, id); err != nil {
+			return fmt.Errorf("error deleting votes of blog: %w", err)
+		}
+		return service.Outbox.Enqueue(ctx, events.BlogDeleted{BlogId: id, AuthorId: blog.AuthorId})
+	})
 	if err != nil {
 		span.SetStatus(codes.Error, "Delete failed")
-		return fmt.Errorf("error deleting blog: %w", err)
+		return err
 	}
 
+	service.Logger.InfoContext(ctx, "Blog deleted", "blog.id", id)
 	span.SetStatus(codes.Ok, "Delete successful")
 	return nil
 }
 
-func (service *BlogService) SetVote(ctx context.Context, blogID int64, userID int64, voteType model.VoteType) (*model.Blog, error) {
+func (service *BlogService) GetBlogsByTopicPage(ctx context.Context, topicType model.BlogTopicType, viewer model.BlogViewer, page useCases.PageRequest) (*useCases.PagedResult[model.Blog], error) {
 	tracer := otel.Tracer("service")
-	ctx, span := tracer.Start(ctx, "SetVote")
+	ctx, span := tracer.Start(ctx, "GetBlogsByTopicPage")
 	defer span.End()
 
-	blog, err := service.BlogRepository.Find(ctx, blogID)
+	span.SetAttributes(telemetry.Topic(string(topicType)))
+
+	result, err := service.BlogRepository.FindAllByTopicPage(ctx, topicType, viewer, page)
 	if err != nil {
-		span.SetStatus(codes.Error, "SetVote failed")
+		span.SetStatus(codes.Error, "GetBlogsByTopicPage failed")
 		return nil, err
 	}
 
-	err = blog.SetVote(userID, voteType)
+	span.SetStatus(codes.Ok, "GetBlogsByTopicPage successful")
+	return result, nil
+}
+
+func (service *BlogService) Publish(ctx context.Context, id int64) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "Publish")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(id))
+
+	blog, err := service.BlogRepository.Find(ctx, id)
+	if err != nil {
+		span.SetStatus(codes.Error, "Publish failed")
+		return nil, err
+	}
+	err = service.Policy.CanEditBlog(ctx, &blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "Publish failed")
+		return nil, err
+	}
+	oldStatus := blog.Status
+	err = blog.Publish(time.Now())
+	if err != nil {
+		span.SetStatus(codes.Error, "Publish failed")
+		return nil, err
+	}
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		if err := service.BlogRepository.Update(ctx, &blog); err != nil {
+			return err
+		}
+		if err := service.enqueueStatusChanged(ctx, &blog, oldStatus); err != nil {
+			return err
+		}
+		return service.enqueueBlogPublished(ctx, &blog)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "Publish failed")
+		return nil, err
+	}
+
+	service.Logger.InfoContext(ctx, "Blog published", "blog.id", id)
+	span.SetStatus(codes.Ok, "Publish successful")
+	return &blog, nil
+}
+
+func (service *BlogService) Unpublish(ctx context.Context, id int64) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "Unpublish")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(id))
+
+	blog, err := service.BlogRepository.Find(ctx, id)
+	if err != nil {
+		span.SetStatus(codes.Error, "Unpublish failed")
+		return nil, err
+	}
+	err = service.Policy.CanEditBlog(ctx, &blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "Unpublish failed")
+		return nil, err
+	}
+	oldStatus := blog.Status
+	err = blog.Unpublish()
+	if err != nil {
+		span.SetStatus(codes.Error, "Unpublish failed")
+		return nil, err
+	}
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		if err := service.BlogRepository.Update(ctx, &blog); err != nil {
+			return err
+		}
+		return service.enqueueStatusChanged(ctx, &blog, oldStatus)
+	})
 	if err != nil {
-		span.SetStatus(codes.Error, "SetVote failed")
+		span.SetStatus(codes.Error, "Unpublish failed")
 		return nil, err
 	}
 
+	service.Logger.InfoContext(ctx, "Blog unpublished", "blog.id", id)
+	span.SetStatus(codes.Ok, "Unpublish successful")
+	return &blog, nil
+}
+
+func (service *BlogService) SchedulePublish(ctx context.Context, id int64, publishAt time.Time) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "SchedulePublish")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(id), attribute.String("blog.publish_at", publishAt.Format(time.RFC3339)))
+
+	blog, err := service.BlogRepository.Find(ctx, id)
+	if err != nil {
+		span.SetStatus(codes.Error, "SchedulePublish failed")
+		return nil, err
+	}
+	err = service.Policy.CanEditBlog(ctx, &blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "SchedulePublish failed")
+		return nil, err
+	}
+	err = blog.SchedulePublish(publishAt, time.Now())
+	if err != nil {
+		span.SetStatus(codes.Error, "SchedulePublish failed")
+		return nil, err
+	}
 	err = service.BlogRepository.Update(ctx, &blog)
 	if err != nil {
-		span.SetStatus(codes.Error, "SetVote failed")
+		span.SetStatus(codes.Error, "SchedulePublish failed")
 		return nil, err
 	}
 
-	span.SetStatus(codes.Ok, "SetVote successful")
+	service.Logger.InfoContext(ctx, "Blog publish scheduled", "blog.id", id, "blog.publish_at", publishAt)
+	span.SetStatus(codes.Ok, "SchedulePublish successful")
 	return &blog, nil
 }
 
-func (service *BlogService) GetBlogsByTopic(ctx context.Context, topicType model.BlogTopicType) ([]model.Blog, error) {
+// PublishDue publishes every scheduled draft whose publish time has passed
+// and returns how many were published by this call.
+func (service *BlogService) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "PublishDue")
+	defer span.End()
+
+	blogs, err := service.BlogRepository.FindScheduledDue(ctx, now)
+	if err != nil {
+		span.SetStatus(codes.Error, "PublishDue failed")
+		return 0, err
+	}
+
+	published := 0
+	for _, blog := range blogs {
+		oldStatus := blog.Status
+		if err := blog.Publish(now); err != nil {
+			continue
+		}
+		ok := false
+		err := service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+			var err error
+			ok, err = service.BlogRepository.PublishScheduled(ctx, &blog, now)
+			if err != nil || !ok {
+				return err
+			}
+			if err := service.enqueueStatusChanged(ctx, &blog, oldStatus); err != nil {
+				return err
+			}
+			return service.enqueueBlogPublished(ctx, &blog)
+		})
+		if err != nil {
+			span.SetStatus(codes.Error, "PublishDue failed")
+			return published, err
+		}
+		if ok {
+			published++
+		}
+	}
+
+	span.SetStatus(codes.Ok, "PublishDue successful")
+	return published, nil
+}
+
+func (service *BlogService) enqueueBlogPublished(ctx context.Context, blog *model.Blog) error {
+	return service.Outbox.Enqueue(ctx, events.BlogPublished{
+		BlogId:      int64(blog.Id),
+		AuthorId:    blog.AuthorId,
+		PublishedAt: blog.Date,
+	})
+}
+
+// enqueueStatusChanged records that the blog's status moved from oldStatus
+// to its current one, if it did.
+func (service *BlogService) enqueueStatusChanged(ctx context.Context, blog *model.Blog, oldStatus model.BlogStatus) error {
+	if blog.Status == oldStatus {
+		return nil
+	}
+	return service.Outbox.Enqueue(ctx, events.BlogStatusChanged{
+		BlogId: int64(blog.Id),
+		From:   string(oldStatus),
+		To:     string(blog.Status),
+	})
+}
+
+func (service *BlogService) FindRevisions(ctx context.Context, blogID int64) ([]model.BlogRevision, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "FindRevisions")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	err := service.canViewRevisions(ctx, blogID)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindRevisions failed")
+		return nil, err
+	}
+	revisions, err := service.RevisionRepository.FindAllByBlog(ctx, blogID)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindRevisions failed")
+		return nil, fmt.Errorf("error fetching revisions for blog ID %d: %w", blogID, err)
+	}
+
+	span.SetStatus(codes.Ok, "FindRevisions successful")
+	return revisions, nil
+}
+
+func (service *BlogService) FindRevision(ctx context.Context, blogID int64, id int64) (*model.BlogRevision, error) {
 	tracer := otel.Tracer("service")
-	ctx, span := tracer.Start(ctx, "GetBlogsByTopic")
+	ctx, span := tracer.Start(ctx, "FindRevision")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"type\": "+string(topicType)+" }"))
+	span.SetAttributes(telemetry.BlogId(blogID), telemetry.RevisionId(id))
 
-	var blogs []model.Blog
-	blogs, _ = service.BlogRepository.FindAllByTopic(ctx, topicType)
+	err := service.canViewRevisions(ctx, blogID)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindRevision failed")
+		return nil, err
+	}
+	revision, err := service.RevisionRepository.Find(ctx, blogID, id)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindRevision failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "FindRevision successful")
+	return &revision, nil
+}
 
-	span.SetStatus(codes.Ok, "GetBlogsByTopic successful")
-	return blogs, nil
+// canViewRevisions checks that the caller may read the history of a blog.
+// Revisions keep content the blog may no longer show, so only its author
+// and administrators may read them.
+func (service *BlogService) canViewRevisions(ctx context.Context, blogID int64) error {
+	blog, err := service.BlogRepository.Find(ctx, blogID)
+	if err != nil {
+		return err
+	}
+	return service.Policy.CanViewRevisions(ctx, &blog)
+}
+
+// RestoreRevision copies a past revision's title and description back onto
+// the blog. The restore is itself recorded as a new revision, so it can be
+// undone too.
+func (service *BlogService) RestoreRevision(ctx context.Context, blogID int64, id int64, editorId int64) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "RestoreRevision")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID), telemetry.RevisionId(id))
+
+	revision, err := service.FindRevision(ctx, blogID, id)
+	if err != nil {
+		span.SetStatus(codes.Error, "RestoreRevision failed")
+		return nil, err
+	}
+
+	blog := &model.Blog{}
+	revision.ApplyTo(blog)
+	restored, err := service.Update(ctx, blogID, editorId, blog, []string{BlogFieldTitle, BlogFieldDescription})
+	if err != nil {
+		span.SetStatus(codes.Error, "RestoreRevision failed")
+		return nil, err
+	}
+
+	service.Logger.InfoContext(ctx, "Blog revision restored", "blog.id", blogID, "blog.revision.id", id)
+	span.SetStatus(codes.Ok, "RestoreRevision successful")
+	return restored, nil
+}
+
+func (service *BlogService) DiffRevisions(ctx context.Context, blogID int64, fromID int64, toID int64) ([]model.FieldDiff, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "DiffRevisions")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID), attribute.Int64("blog.revision.from", fromID), attribute.Int64("blog.revision.to", toID))
+
+	err := service.canViewRevisions(ctx, blogID)
+	if err != nil {
+		span.SetStatus(codes.Error, "DiffRevisions failed")
+		return nil, err
+	}
+	from, err := service.RevisionRepository.Find(ctx, blogID, fromID)
+	if err != nil {
+		span.SetStatus(codes.Error, "DiffRevisions failed")
+		return nil, err
+	}
+	to, err := service.RevisionRepository.Find(ctx, blogID, toID)
+	if err != nil {
+		span.SetStatus(codes.Error, "DiffRevisions failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "DiffRevisions successful")
+	return from.Diff(&to), nil
+}
+
+// AdjustCommentCount applies a change in the number of comments to the blog
+// and recalculates its status, which depends on that number.
+func (service *BlogService) AdjustCommentCount(ctx context.Context, blogID int64, delta int64) error {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "AdjustCommentCount")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID), attribute.Int64("blog.comment_count.delta", delta))
+
+	blog, err := service.BlogRepository.IncrementCommentCount(ctx, blogID, delta)
+	if err != nil {
+		span.SetStatus(codes.Error, "AdjustCommentCount failed")
+		return fmt.Errorf("error updating comment count of blog %d: %w", blogID, err)
+	}
+	err = service.refreshStatus(ctx, &blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "AdjustCommentCount failed")
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "AdjustCommentCount successful")
+	return nil
+}
+
+// refreshStatus recalculates the status of a freshly loaded blog and, if it
+// changed, stores it and records a status change event.
+func (service *BlogService) refreshStatus(ctx context.Context, blog *model.Blog) error {
+	oldStatus := blog.Status
+	blog.UpdateBlogStatus()
+	if blog.Status == oldStatus {
+		return nil
+	}
+	err := service.BlogRepository.UpdateStatus(ctx, int64(blog.Id), blog.Status)
+	if err != nil {
+		return fmt.Errorf("error updating status of blog %d: %w", blog.Id, err)
+	}
+	return service.enqueueStatusChanged(ctx, blog, oldStatus)
+}
+
+// AdjustVoteCounts applies a change in votes to the blog's counters and
+// recalculates its status, which depends on them.
+func (service *BlogService) AdjustVoteCounts(ctx context.Context, blogID int64, change model.VoteTally) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "AdjustVoteCounts")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	blog, err := service.BlogRepository.IncrementVoteCounts(ctx, blogID, change)
+	if err != nil {
+		span.SetStatus(codes.Error, "AdjustVoteCounts failed")
+		return nil, fmt.Errorf("error updating vote counts of blog %d: %w", blogID, err)
+	}
+	err = service.refreshStatus(ctx, &blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "AdjustVoteCounts failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "AdjustVoteCounts successful")
+	return &blog, nil
 }
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"BlogApplication/model"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testBlog() model.Blog {
+	return model.Blog{
+		Id:          1,
+		Title:       "Old title",
+		Description: "Old description",
+		BlogTopic:   model.BlogTopicTypeFood,
+		Visibility:  model.PublicBlog,
+		Status:      model.Published,
+	}
+}
+
+func TestApplyBlogFieldsHonoursTheMask(t *testing.T) {
+	patch := &model.Blog{
+		Title:       "New title",
+		Description: "New description",
+		BlogTopic:   model.BlogTopicTypeArt,
+		Visibility:  model.PrivateBlog,
+	}
+
+	blog := testBlog()
+	fields, err := applyBlogFields(&blog, patch, []string{BlogFieldTitle})
+	if err != nil {
+		t.Fatalf("applyBlogFields: %v", err)
+	}
+	want := testBlog()
+	want.Title = "New title"
+	if !reflect.DeepEqual(blog, want) {
+		t.Errorf("blog = %+v, want only the title changed", blog)
+	}
+	if !reflect.DeepEqual(fields, []string{BlogFieldTitle}) {
+		t.Errorf("fields = %v", fields)
+	}
+
+	blog = testBlog()
+	fields, err = applyBlogFields(&blog, patch, []string{BlogFieldDescription, BlogFieldTopic})
+	if err != nil {
+		t.Fatalf("applyBlogFields: %v", err)
+	}
+	if blog.Title != "Old title" || blog.Description != "New description" || blog.BlogTopic != model.BlogTopicTypeArt || blog.Visibility != model.PublicBlog {
+		t.Errorf("blog = %+v, want description and topic changed", blog)
+	}
+	if len(fields) != 2 {
+		t.Errorf("fields = %v", fields)
+	}
+}
+
+func TestApplyBlogFieldsWithoutMaskUpdatesEverything(t *testing.T) {
+	patch := &model.Blog{
+		Title:       "New title",
+		Description: "New description",
+		BlogTopic:   model.BlogTopicTypeArt,
+		Visibility:  model.PrivateBlog,
+	}
+	blog := testBlog()
+	fields, err := applyBlogFields(&blog, patch, nil)
+	if err != nil {
+		t.Fatalf("applyBlogFields: %v", err)
+	}
+	if blog.Title != patch.Title || blog.Description != patch.Description || blog.BlogTopic != patch.BlogTopic || blog.Visibility != patch.Visibility {
+		t.Errorf("blog = %+v, want every field from the patch", blog)
+	}
+	if blog.Id != 1 || blog.Status != model.Published {
+		t.Errorf("fields outside the mask changed: %+v", blog)
+	}
+	if len(fields) != 4 {
+		t.Errorf("fields = %v, want all four", fields)
+	}
+}
+
+func TestApplyBlogFieldsWithoutMaskKeepsUnsetVisibility(t *testing.T) {
+	blog := testBlog()
+	fields, err := applyBlogFields(&blog, &model.Blog{Title: "New title", Description: "New description", BlogTopic: model.BlogTopicTypeArt}, nil)
+	if err != nil {
+		t.Fatalf("applyBlogFields: %v", err)
+	}
+	if blog.Visibility != model.PublicBlog {
+		t.Errorf("visibility = %q, want it left alone", blog.Visibility)
+	}
+	if !reflect.DeepEqual(fields, []string{BlogFieldTitle, BlogFieldDescription, BlogFieldTopic}) {
+		t.Errorf("fields = %v", fields)
+	}
+}
+
+// TestApplyUpdateWithoutMask is a full edit that doesn't mention
+// visibility, which needs no moderator and keeps the blog as it was.
+func TestApplyUpdateWithoutMask(t *testing.T) {
+	service := &BlogService{Policy: &Policy{}}
+	patch := &model.Blog{Title: "New title", Description: "New description", BlogTopic: model.BlogTopicTypeArt}
+
+	for _, caller := range []string{"author", "moderator"} {
+		blog := testBlog()
+		blog.AuthorId = policyAuthor
+		blog.Visibility = model.PrivateBlog
+		fields, err := service.applyUpdate(policyCallers[caller], &blog, patch, nil)
+		if err != nil {
+			t.Errorf("%s: applyUpdate: %v", caller, err)
+			continue
+		}
+		if blog.Title != "New title" || blog.Visibility != model.PrivateBlog || len(fields) != 3 {
+			t.Errorf("%s: blog = %+v, fields = %v", caller, blog, fields)
+		}
+	}
+
+	// Naming a different visibility is still a moderator's call.
+	blog := testBlog()
+	blog.AuthorId = policyAuthor
+	patch.Visibility = model.PrivateBlog
+	if _, err := service.applyUpdate(policyCallers["author"], &blog, patch, nil); model.KindOf(err) != model.KindPermissionDenied {
+		t.Errorf("author changing visibility: %v, want permission denied", err)
+	}
+}
+
+func TestApplyBlogFieldsRejectsUnknownFields(t *testing.T) {
+	blog := testBlog()
+	_, err := applyBlogFields(&blog, &model.Blog{Status: model.Famous}, []string{"status"})
+	if model.KindOf(err) != model.KindInvalidArgument {
+		t.Fatalf("got %v, want an invalid argument", err)
+	}
+	var domainError *model.DomainError
+	if !errors.As(err, &domainError) || domainError.Violations[0].Field != "update_mask" {
+		t.Errorf("violations = %+v, want one on update_mask", domainError)
+	}
+	if blog.Status != model.Published {
+		t.Errorf("status changed to %s", blog.Status)
+	}
+}
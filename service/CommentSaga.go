@@ -0,0 +1,88 @@
+package service
+
+import (
+	"BlogApplication/auth"
+	"BlogApplication/events"
+	"BlogApplication/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Subjects the comment-creation saga answers a CommentCreated event on.
+// Rollbacks are acknowledged with a CommentCreationCompensated event.
+const (
+	CommentCreationConfirmedSubject = "comment.creation.confirmed"
+	CommentCreationRollbackSubject  = "comment.creation.rollback"
+)
+
+const (
+	CommentSagaStream  = "COMMENT_SAGA"
+	CommentSagaDurable = "blog-service-comment-saga"
+)
+
+// CommentSaga is this service's part in the comment-creation saga. It
+// consumes confirmations and rollbacks from a durable JetStream consumer, so
+// none are lost while the service is down.
+type CommentSaga struct {
+	CommentService *CommentService
+	JetStream      jetstream.JetStream
+	DeadLetters    *events.DeadLetterQueue
+	MaxDeliver     int
+	Logger         *slog.Logger
+}
+
+type commentSagaEvent struct {
+	CommentID int64 `json:"comment_id"`
+}
+
+// Start starts consuming in the background. Stop the returned context to
+// stop consuming.
+func (saga *CommentSaga) Start(ctx context.Context) (jetstream.ConsumeContext, error) {
+	consumer := &events.DurableConsumer{
+		JetStream:   saga.JetStream,
+		Stream:      CommentSagaStream,
+		Subjects:    []string{CommentCreationConfirmedSubject, CommentCreationRollbackSubject},
+		Durable:     CommentSagaDurable,
+		MaxDeliver:  saga.MaxDeliver,
+		DeadLetters: saga.DeadLetters,
+		Handler:     saga.handle,
+		Logger:      saga.Logger,
+	}
+	return consumer.Start(ctx)
+}
+
+func (saga *CommentSaga) handle(ctx context.Context, msg jetstream.Msg) error {
+	var event commentSagaEvent
+	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+		return events.Permanent(fmt.Errorf("malformed %s message: %w", msg.Subject(), err))
+	}
+	if event.CommentID <= 0 {
+		return events.Permanent(fmt.Errorf("%s message has no comment id", msg.Subject()))
+	}
+
+	ctx = auth.SystemContext(ctx)
+	var err error
+	switch msg.Subject() {
+	case CommentCreationConfirmedSubject:
+		err = saga.CommentService.ConfirmCreation(ctx, event.CommentID)
+	case CommentCreationRollbackSubject:
+		err = saga.CommentService.CompensateCreation(ctx, event.CommentID)
+	default:
+		return events.Permanent(fmt.Errorf("unexpected subject %s", msg.Subject()))
+	}
+	if err != nil {
+		// Domain errors, such as confirming a comment that doesn't exist,
+		// won't go away on redelivery.
+		if model.KindOf(err) != 0 {
+			return events.Permanent(err)
+		}
+		return err
+	}
+
+	saga.Logger.InfoContext(ctx, "Handled comment saga event", "messaging.destination.name", msg.Subject(), "comment.id", event.CommentID)
+	return nil
+}
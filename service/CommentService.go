@@ -2,20 +2,35 @@ package service
 
 import (
 	"BlogApplication/dto"
+	"BlogApplication/events"
 	"BlogApplication/model"
 	"BlogApplication/repository"
+	"BlogApplication/telemetry"
+	"BlogApplication/useCases"
 	"context"
-	"encoding/json"
 	"fmt"
+	"log/slog"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
-	"gorm.io/gorm"
 )
 
+// DefaultMaxCommentDepth is how deeply replies may nest when no MaxDepth is
+// configured.
+const DefaultMaxCommentDepth = 5
+
 type CommentService struct {
 	CommentRepo *repository.CommentRepository
+	BlogService *BlogService
+	MaxDepth    int
+	// SagaEnabled is set when the comment-creation saga runs. Without it
+	// nothing would ever confirm a comment, so new comments are confirmed and
+	// counted as soon as they're created.
+	SagaEnabled bool
+	Policy      *Policy
+	Outbox      *Outbox
+	Logger      *slog.Logger
 }
 
 func (service *CommentService) FindById(ctx context.Context, id int) (*model.Comment, error) {
@@ -23,14 +38,11 @@ func (service *CommentService) FindById(ctx context.Context, id int) (*model.Com
 	ctx, span := tracer.Start(ctx, "FindById")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.CommentId(int64(id)))
 
 	comment, err := service.CommentRepo.FindById(ctx, id)
 	if err != nil {
 		span.SetStatus(codes.Error, "FindById failed")
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("comment with id %d not found", id)
-		}
 		return nil, err
 	}
 
@@ -43,33 +55,67 @@ func (service *CommentService) Create(ctx context.Context, commentRequest *dto.C
 	ctx, span := tracer.Start(ctx, "Create")
 	defer span.End()
 
-	reqData, err := json.Marshal(commentRequest)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return nil, err
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(commentRequest.BlogId), telemetry.UserId(commentRequest.AuthorId), telemetry.Text("comment.text", commentRequest.Text))
 
 	comment := model.Comment{
 		AuthorId:  commentRequest.AuthorId,
 		BlogId:    commentRequest.BlogId,
 		CreatedAt: commentRequest.CreatedAt,
 		Text:      commentRequest.Text,
+		SagaState: service.initialSagaState(),
 	}
 
-	err = comment.Validate()
+	err := comment.Validate()
 	if err != nil {
 		span.SetStatus(codes.Error, "Create failed")
 		return nil, fmt.Errorf("error validating comment: %w", err)
 	}
 
-	createdComment, err := service.CommentRepo.Create(ctx, &comment)
+	if commentRequest.ParentId != nil {
+		parent, err := service.CommentRepo.FindById(ctx, *commentRequest.ParentId)
+		if err != nil {
+			span.SetStatus(codes.Error, "Create failed")
+			return nil, err
+		}
+		err = comment.ReplyTo(&parent, service.maxDepth())
+		if err != nil {
+			span.SetStatus(codes.Error, "Create failed")
+			return nil, fmt.Errorf("error validating comment: %w", err)
+		}
+	}
+
+	blog, err := service.BlogService.Find(ctx, comment.BlogId)
+	if err != nil {
+		span.SetStatus(codes.Error, "Create failed")
+		return nil, err
+	}
+
+	var createdComment *model.Comment
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		var err error
+		createdComment, err = service.CommentRepo.Create(ctx, &comment)
+		if err != nil {
+			return fmt.Errorf("error creating comment: %w", err)
+		}
+		if createdComment.IsCounted() {
+			err = service.BlogService.AdjustCommentCount(ctx, createdComment.BlogId, 1)
+			if err != nil {
+				return err
+			}
+		}
+		return service.Outbox.Enqueue(ctx, events.CommentCreated{
+			UserId:    createdComment.AuthorId,
+			AuthorId:  blog.AuthorId,
+			CommentId: createdComment.Id,
+		})
+	})
 	if err != nil {
 		span.SetStatus(codes.Error, "Create failed")
-		return nil, fmt.Errorf("error creating comment: %w", err)
+		return nil, err
 	}
 
+	telemetry.CommentCreated(ctx)
+	service.Logger.InfoContext(ctx, "Comment created", "comment.id", createdComment.Id, "blog.id", createdComment.BlogId)
 	span.SetStatus(codes.Ok, "Create successful")
 	return createdComment, nil
 }
@@ -79,13 +125,22 @@ func (service *CommentService) Update(ctx context.Context, comment *dto.CommentU
 	ctx, span := tracer.Start(ctx, "Update")
 	defer span.End()
 
-	reqData, err := json.Marshal(comment)
+	span.SetAttributes(telemetry.CommentId(comment.ID), telemetry.Text("comment.text", comment.Text))
+
+	existing, err := service.CommentRepo.FindById(ctx, int(comment.ID))
+	if err != nil {
+		span.SetStatus(codes.Error, "Update failed")
+		return err
+	}
+	err = service.Policy.CanEditComment(ctx, &existing)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
+		span.SetStatus(codes.Error, "Update failed")
 		return err
 	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	if existing.Deleted {
+		span.SetStatus(codes.Error, "Update failed")
+		return model.FailedPrecondition("comment with id %d is deleted", comment.ID)
+	}
 
 	err = service.CommentRepo.Update(ctx, comment)
 	if err != nil {
@@ -93,6 +148,7 @@ func (service *CommentService) Update(ctx context.Context, comment *dto.CommentU
 		return fmt.Errorf("error updating comment: %w", err)
 	}
 
+	service.Logger.InfoContext(ctx, "Comment updated", "comment.id", comment.ID)
 	span.SetStatus(codes.Ok, "Update successful")
 	return nil
 }
@@ -102,25 +158,123 @@ func (service *CommentService) Delete(ctx context.Context, id int64) error {
 	ctx, span := tracer.Start(ctx, "Delete")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.CommentId(id))
 
-	err := service.CommentRepo.Delete(ctx, int64(id))
+	comment, err := service.CommentRepo.FindById(ctx, int(id))
+	if err != nil {
+		span.SetStatus(codes.Error, "Delete failed")
+		return err
+	}
+	blog, err := service.BlogService.Find(ctx, comment.BlogId)
+	if err != nil {
+		span.SetStatus(codes.Error, "Delete failed")
+		return err
+	}
+	err = service.Policy.CanDeleteComment(ctx, &comment, blog)
+	if err != nil {
+		span.SetStatus(codes.Error, "Delete failed")
+		return err
+	}
+	replies, err := service.CommentRepo.CountReplies(ctx, id)
 	if err != nil {
 		span.SetStatus(codes.Error, "Delete failed")
 		return fmt.Errorf("error deleting comment: %w", err)
 	}
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		var err error
+		if replies > 0 {
+			err = service.CommentRepo.Tombstone(ctx, id)
+		} else {
+			err = service.CommentRepo.Delete(ctx, id)
+		}
+		if err != nil {
+			return fmt.Errorf("error deleting comment: %w", err)
+		}
+
+		// Tombstones were already subtracted from the count when they were
+		// deleted, and unconfirmed comments were never added to it.
+		if !comment.IsCounted() {
+			return nil
+		}
+		return service.BlogService.AdjustCommentCount(ctx, comment.BlogId, -1)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "Delete failed")
+		return err
+	}
 
+	service.Logger.InfoContext(ctx, "Comment deleted", "comment.id", id)
 	span.SetStatus(codes.Ok, "Delete successful")
 	return nil
 }
 
+// ConfirmCreation ends the creation saga of a pending comment successfully,
+// making it visible and counting it towards its blog. Confirming a comment
+// that is no longer pending changes nothing.
+func (service *CommentService) ConfirmCreation(ctx context.Context, id int64) error {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "ConfirmCreation")
+	defer span.End()
+
+	span.SetAttributes(telemetry.CommentId(id))
+
+	err := service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		comment, changed, err := service.CommentRepo.SetSagaState(ctx, id, []model.CommentSagaState{model.CommentPending}, model.CommentConfirmed)
+		if err != nil || !changed || comment.Deleted {
+			return err
+		}
+		return service.BlogService.AdjustCommentCount(ctx, comment.BlogId, 1)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "ConfirmCreation failed")
+		return err
+	}
+
+	service.Logger.InfoContext(ctx, "Comment creation confirmed", "comment.id", id)
+	span.SetStatus(codes.Ok, "ConfirmCreation successful")
+	return nil
+}
+
+// CompensateCreation undoes the creation of a comment by hiding it for good,
+// and replies with a CommentCreationCompensated event. Only the first call
+// changes anything, but every call replies, so a redelivered rollback still
+// gets an answer. A comment that doesn't exist counts as compensated.
+func (service *CommentService) CompensateCreation(ctx context.Context, id int64) error {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "CompensateCreation")
+	defer span.End()
+
+	span.SetAttributes(telemetry.CommentId(id))
+
+	from := []model.CommentSagaState{"", model.CommentPending, model.CommentConfirmed}
+	err := service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		comment, changed, err := service.CommentRepo.SetSagaState(ctx, id, from, model.CommentCompensated)
+		if err != nil && model.KindOf(err) != model.KindNotFound {
+			return err
+		}
+		if changed && comment.IsCounted() {
+			err = service.BlogService.AdjustCommentCount(ctx, comment.BlogId, -1)
+			if err != nil {
+				return err
+			}
+		}
+		return service.Outbox.Enqueue(ctx, events.CommentCreationCompensated{CommentId: id})
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "CompensateCreation failed")
+		return err
+	}
+
+	service.Logger.InfoContext(ctx, "Comment creation rolled back", "comment.id", id)
+	span.SetStatus(codes.Ok, "CompensateCreation successful")
+	return nil
+}
+
 func (service *CommentService) GetAll(ctx context.Context) ([]model.Comment, error) {
 	tracer := otel.Tracer("service")
 	ctx, span := tracer.Start(ctx, "GetAll")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{}"))
-
 	comments, err := service.CommentRepo.GetAll(ctx)
 	if err != nil {
 		span.SetStatus(codes.Error, "GetAll failed")
@@ -131,12 +285,29 @@ func (service *CommentService) GetAll(ctx context.Context) ([]model.Comment, err
 	return comments, nil
 }
 
+func (service *CommentService) GetAllPage(ctx context.Context, page useCases.PageRequest) (*useCases.PagedResult[model.Comment], error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "GetAllPage")
+	defer span.End()
+
+	span.SetAttributes(telemetry.PageSize(page.Limit()))
+
+	result, err := service.CommentRepo.GetAllPage(ctx, page)
+	if err != nil {
+		span.SetStatus(codes.Error, "GetAllPage failed")
+		return nil, fmt.Errorf("error fetching comments: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "GetAllPage successful")
+	return result, nil
+}
+
 func (service *CommentService) GetAllBlogComments(ctx context.Context, blogID int64) ([]model.Comment, error) {
 	tracer := otel.Tracer("service")
 	ctx, span := tracer.Start(ctx, "GetAllBlogComments")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(blogID)+" }"))
+	span.SetAttributes(telemetry.BlogId(blogID))
 
 	comments, err := service.CommentRepo.GetAllByBlog(ctx, int64(blogID))
 	if err != nil {
@@ -147,3 +318,93 @@ func (service *CommentService) GetAllBlogComments(ctx context.Context, blogID in
 	span.SetStatus(codes.Ok, "GetAllBlogComments successful")
 	return comments, nil
 }
+
+func (service *CommentService) GetAllBlogCommentsPage(ctx context.Context, blogID int64, page useCases.PageRequest) (*useCases.PagedResult[model.Comment], error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "GetAllBlogCommentsPage")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	result, err := service.CommentRepo.GetAllByBlogPage(ctx, blogID, page)
+	if err != nil {
+		span.SetStatus(codes.Error, "GetAllBlogCommentsPage failed")
+		return nil, fmt.Errorf("error fetching comments for blog ID %d: %w", blogID, err)
+	}
+
+	span.SetStatus(codes.Ok, "GetAllBlogCommentsPage successful")
+	return result, nil
+}
+
+// GetThread returns the comment thread rooted at rootID, or every thread of
+// the blog when rootID is zero.
+func (service *CommentService) GetThread(ctx context.Context, blogID int64, rootID int64) ([]*model.CommentNode, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "GetThread")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID), attribute.Int64("comment.root_id", rootID))
+
+	comments, err := service.CommentRepo.FindThread(ctx, blogID, rootID)
+	if err != nil {
+		span.SetStatus(codes.Error, "GetThread failed")
+		return nil, fmt.Errorf("error fetching comment thread for blog ID %d: %w", blogID, err)
+	}
+
+	span.SetStatus(codes.Ok, "GetThread successful")
+	return model.BuildCommentTree(comments), nil
+}
+
+// initialSagaState is the state a new comment starts in: pending until the
+// saga confirms it, or confirmed straight away when the saga doesn't run.
+func (service *CommentService) initialSagaState() model.CommentSagaState {
+	if service.SagaEnabled {
+		return model.CommentPending
+	}
+	return model.CommentConfirmed
+}
+
+func (service *CommentService) maxDepth() int {
+	if service.MaxDepth > 0 {
+		return service.MaxDepth
+	}
+	return DefaultMaxCommentDepth
+}
+
+// BackfillCommentCounts counts the comments of blogs stored before comment
+// counts were tracked on the blog document, and updates their status.
+func (service *CommentService) BackfillCommentCounts(ctx context.Context) error {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "BackfillCommentCounts")
+	defer span.End()
+
+	blogs, err := service.BlogService.BlogRepository.FindMissingCommentCount(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, "BackfillCommentCounts failed")
+		return err
+	}
+	for _, blog := range blogs {
+		count, err := service.CommentRepo.CountByBlog(ctx, int64(blog.Id))
+		if err != nil {
+			span.SetStatus(codes.Error, "BackfillCommentCounts failed")
+			return err
+		}
+		err = service.BlogService.BlogRepository.SetCommentCount(ctx, int64(blog.Id), count)
+		if err != nil {
+			span.SetStatus(codes.Error, "BackfillCommentCounts failed")
+			return err
+		}
+		blog.CommentCount = count
+		err = service.BlogService.refreshStatus(ctx, &blog)
+		if err != nil {
+			span.SetStatus(codes.Error, "BackfillCommentCounts failed")
+			return err
+		}
+	}
+
+	if len(blogs) > 0 {
+		service.Logger.InfoContext(ctx, "Comment counts backfilled", "blogs", len(blogs))
+	}
+	span.SetStatus(codes.Ok, "BackfillCommentCounts successful")
+	return nil
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"BlogApplication/model"
+	"testing"
+)
+
+func TestNewCommentsWaitForTheSagaOnlyWhenItRuns(t *testing.T) {
+	withSaga := &CommentService{SagaEnabled: true}
+	if state := withSaga.initialSagaState(); state != model.CommentPending {
+		t.Errorf("with the saga, new comments start %q, want pending", state)
+	}
+
+	// Nothing confirms comments when the saga is off, so they must be
+	// listed and counted from the start.
+	withoutSaga := &CommentService{}
+	comment := model.Comment{SagaState: withoutSaga.initialSagaState()}
+	if comment.SagaState != model.CommentConfirmed || !comment.IsCounted() {
+		t.Errorf("without the saga, new comments start %q, want confirmed and counted", comment.SagaState)
+	}
+}
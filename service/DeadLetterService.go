@@ -0,0 +1,86 @@
+package service
+
+import (
+	"BlogApplication/events"
+	"BlogApplication/model"
+	"BlogApplication/telemetry"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	DefaultDeadLetterPageSize = 20
+	MaxDeadLetterPageSize     = 100
+)
+
+// DeadLetterService lets administrators inspect and replay messages the
+// consumers gave up on.
+type DeadLetterService struct {
+	Queue  *events.DeadLetterQueue
+	Policy *Policy
+	Logger *slog.Logger
+}
+
+// List returns a page of dead letters starting at sequence from, and the
+// sequence the next page starts at, or zero after the last page.
+func (service *DeadLetterService) List(ctx context.Context, from uint64, pageSize int) ([]events.DeadLetter, uint64, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "List")
+	defer span.End()
+
+	span.SetAttributes(telemetry.DeadLetterSeq(from))
+
+	err := service.Policy.CanManageDeadLetters(ctx, ActionListDeadLetters, 0)
+	if err != nil {
+		span.SetStatus(codes.Error, "List failed")
+		return nil, 0, err
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultDeadLetterPageSize
+	}
+	pageSize = min(pageSize, MaxDeadLetterPageSize)
+
+	deadLetters, next, err := service.Queue.List(ctx, from, pageSize)
+	if err != nil {
+		span.SetStatus(codes.Error, "List failed")
+		return nil, 0, fmt.Errorf("error listing dead letters: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "List successful")
+	return deadLetters, next, nil
+}
+
+// Replay publishes a dead letter on its original subject again, so its
+// consumer gets another round of attempts.
+func (service *DeadLetterService) Replay(ctx context.Context, seq uint64) error {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "Replay")
+	defer span.End()
+
+	span.SetAttributes(telemetry.DeadLetterSeq(seq))
+
+	err := service.Policy.CanManageDeadLetters(ctx, ActionReplayDeadLetter, seq)
+	if err != nil {
+		span.SetStatus(codes.Error, "Replay failed")
+		return err
+	}
+
+	err = service.Queue.Replay(ctx, seq)
+	if errors.Is(err, events.ErrDeadLetterNotFound) {
+		span.SetStatus(codes.Error, "Replay failed")
+		return model.NotFound("dead letter with sequence %d not found", seq)
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "Replay failed")
+		return err
+	}
+
+	service.Logger.InfoContext(ctx, "Dead letter replayed", "dead_letter.sequence", seq)
+	span.SetStatus(codes.Ok, "Replay successful")
+	return nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"BlogApplication/model"
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// LoggingService lets administrators change how much the service logs
+// without restarting it.
+type LoggingService struct {
+	// Level is the level shared by every logger of the service.
+	Level  *slog.LevelVar
+	Policy *Policy
+	Logger *slog.Logger
+}
+
+// SetLevel changes the log level and returns the one it replaced.
+func (service *LoggingService) SetLevel(ctx context.Context, level string) (slog.Level, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "SetLevel")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("log.level", level))
+
+	err := service.Policy.CanSetLogLevel(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, "SetLevel failed")
+		return 0, err
+	}
+
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		span.SetStatus(codes.Error, "SetLevel failed")
+		return 0, model.InvalidField("level", "level must be debug, info, warn or error, got %q", level)
+	}
+
+	previous := service.Level.Level()
+	service.Level.Set(parsed)
+	service.Logger.InfoContext(ctx, "Log level changed", "log.level", parsed, "log.previous_level", previous)
+
+	span.SetStatus(codes.Ok, "SetLevel successful")
+	return previous, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"BlogApplication/model"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestLoggingServiceSetLevel(t *testing.T) {
+	level := &slog.LevelVar{}
+	service := &LoggingService{
+		Level:  level,
+		Policy: &Policy{},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	previous, err := service.SetLevel(policyCallers["admin"], "debug")
+	if err != nil || previous != slog.LevelInfo || level.Level() != slog.LevelDebug {
+		t.Errorf("SetLevel = %v, %v; level is now %v", previous, err, level.Level())
+	}
+
+	if _, err := service.SetLevel(policyCallers["moderator"], "error"); model.KindOf(err) != model.KindPermissionDenied {
+		t.Errorf("moderator got %v, want permission denied", err)
+	}
+	if _, err := service.SetLevel(policyCallers["admin"], "loud"); model.KindOf(err) != model.KindInvalidArgument {
+		t.Errorf("unknown level got %v, want invalid argument", err)
+	}
+	if level.Level() != slog.LevelDebug {
+		t.Errorf("level = %v after rejected calls, want it unchanged", level.Level())
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"BlogApplication/events"
+	"BlogApplication/model"
+	"BlogApplication/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Outbox records events in the same transaction as the change that raised
+// them, so an event is published if and only if its change was committed.
+// The OutboxRelay does the publishing.
+type Outbox struct {
+	Transactions *repository.TransactionRunner
+	Repository   *repository.OutboxRepository
+	// Relay, if set, is woken after each commit so events go out promptly.
+	Relay *OutboxRelay
+}
+
+// Atomically runs fn in a transaction. Repository calls and Enqueue calls
+// made with the context fn receives are committed or discarded together.
+func (outbox *Outbox) Atomically(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := outbox.Transactions.WithTransaction(ctx, fn)
+	if err != nil {
+		return err
+	}
+	if outbox.Relay != nil {
+		outbox.Relay.Wake()
+	}
+	return nil
+}
+
+// Enqueue stores an event for publishing.
+func (outbox *Outbox) Enqueue(ctx context.Context, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding %s event: %w", event.Subject(), err)
+	}
+	message := &model.OutboxMessage{
+		Subject:      event.Subject(),
+		Version:      event.SchemaVersion(),
+		Payload:      data,
+		CreatedAt:    time.Now(),
+		TraceContext: events.TraceContext(ctx),
+	}
+	err = outbox.Repository.Create(ctx, message)
+	if err != nil {
+		return fmt.Errorf("error storing %s event: %w", event.Subject(), err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"BlogApplication/events"
+	"BlogApplication/model"
+	"BlogApplication/repository"
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	DefaultOutboxBatchSize  = 100
+	DefaultOutboxMaxBackoff = time.Minute
+)
+
+// OutboxRelay publishes the outbox backlog in order. A message is marked
+// sent only after the publisher has flushed it to the broker, so delivery is
+// at least once; the event id header lets consumers drop duplicates. After a failure the relay waits twice as
+// long before each new attempt, up to MaxBackoff.
+type OutboxRelay struct {
+	OutboxRepository *repository.OutboxRepository
+	Publisher        events.Publisher
+	Interval         time.Duration
+	BatchSize        int64
+	MaxBackoff       time.Duration
+	Logger           *slog.Logger
+
+	wake     chan struct{}
+	wakeOnce sync.Once
+}
+
+// Wake asks the relay to check the backlog now rather than at its next tick.
+func (relay *OutboxRelay) Wake() {
+	select {
+	case relay.wakeChannel() <- struct{}{}:
+	default:
+	}
+}
+
+func (relay *OutboxRelay) wakeChannel() chan struct{} {
+	relay.wakeOnce.Do(func() {
+		relay.wake = make(chan struct{}, 1)
+	})
+	return relay.wake
+}
+
+// Run blocks, relaying the backlog every Interval, or sooner when woken,
+// until ctx is done.
+func (relay *OutboxRelay) Run(ctx context.Context) {
+	wake := relay.wakeChannel()
+	delay := relay.Interval
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+		}
+
+		_, err := relay.RelayPending(ctx)
+		if err != nil {
+			relay.Logger.ErrorContext(ctx, "Failed to relay outbox", "error", err)
+			delay = min(delay*2, relay.maxBackoff())
+		} else {
+			delay = relay.Interval
+		}
+		timer.Reset(delay)
+	}
+}
+
+// RelayPending publishes pending messages until the backlog is empty or a
+// batch fails, and returns how many were sent.
+func (relay *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "RelayPending")
+	defer span.End()
+
+	sent := 0
+	for {
+		messages, err := relay.OutboxRepository.FindPending(ctx, relay.batchSize())
+		if err != nil {
+			span.SetStatus(codes.Error, "RelayPending failed")
+			return sent, err
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		ids, err := publishBatch(ctx, relay.Publisher, messages)
+		if err != nil {
+			if markErr := relay.OutboxRepository.MarkFailed(ctx, ids, err.Error()); markErr != nil {
+				relay.Logger.ErrorContext(ctx, "Failed to record outbox failure", "error", markErr)
+			}
+			span.SetStatus(codes.Error, "RelayPending failed")
+			return sent, err
+		}
+
+		err = relay.OutboxRepository.MarkSent(ctx, ids, time.Now())
+		if err != nil {
+			span.SetStatus(codes.Error, "RelayPending failed")
+			return sent, err
+		}
+		sent += len(messages)
+	}
+
+	span.SetAttributes(attribute.Int("outbox.sent", sent))
+	span.SetStatus(codes.Ok, "RelayPending successful")
+	return sent, nil
+}
+
+// publishBatch publishes messages in order and flushes them. It stops at the
+// first failure and returns the ids of the messages it got to, the failed
+// one included, so only those are marked failed.
+func publishBatch(ctx context.Context, publisher events.Publisher, messages []model.OutboxMessage) ([]int, error) {
+	ids := make([]int, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.Id)
+		if err := publisher.Publish(ctx, toEventMessage(&message)); err != nil {
+			return ids, err
+		}
+	}
+	return ids, publisher.Flush(ctx)
+}
+
+func (relay *OutboxRelay) batchSize() int64 {
+	if relay.BatchSize > 0 {
+		return relay.BatchSize
+	}
+	return DefaultOutboxBatchSize
+}
+
+// toEventMessage rebuilds the message an outbox row was enqueued as. Its id
+// is derived from the row, so a republished row keeps the same event id.
+func toEventMessage(message *model.OutboxMessage) *events.Message {
+	return &events.Message{
+		Id:           "blog-service-" + strconv.Itoa(message.Id),
+		Subject:      message.Subject,
+		Version:      message.Version,
+		OccurredAt:   message.CreatedAt,
+		Data:         message.Payload,
+		TraceContext: message.TraceContext,
+	}
+}
+
+func (relay *OutboxRelay) maxBackoff() time.Duration {
+	if relay.MaxBackoff > 0 {
+		return relay.MaxBackoff
+	}
+	return DefaultOutboxMaxBackoff
+}
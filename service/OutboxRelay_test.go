@@ -0,0 +1,102 @@
+package service
+
+import (
+	"BlogApplication/events"
+	"BlogApplication/model"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakePublisher records what it publishes and fails the message whose id is
+// failId, or the flush when flushErr is set.
+type fakePublisher struct {
+	failId    string
+	flushErr  error
+	published []string
+}
+
+func (publisher *fakePublisher) Publish(ctx context.Context, message *events.Message) error {
+	if message.Id == publisher.failId {
+		return errors.New("broker refused " + message.Id)
+	}
+	publisher.published = append(publisher.published, message.Id)
+	return nil
+}
+
+func (publisher *fakePublisher) Flush(ctx context.Context) error {
+	return publisher.flushErr
+}
+
+func outboxRows(ids ...int) []model.OutboxMessage {
+	rows := make([]model.OutboxMessage, len(ids))
+	for i, id := range ids {
+		rows[i] = model.OutboxMessage{Id: id, Subject: events.BlogCreatedSubject, Version: 1}
+	}
+	return rows
+}
+
+func TestPublishBatch(t *testing.T) {
+	ctx := context.Background()
+	flushErr := errors.New("flush timed out")
+	tests := []struct {
+		name          string
+		publisher     *fakePublisher
+		wantIds       []int
+		wantPublished []string
+		wantErr       bool
+	}{
+		{"all sent", &fakePublisher{}, []int{7, 8, 9}, []string{"blog-service-7", "blog-service-8", "blog-service-9"}, false},
+		// Only the rows up to the failed one were attempted; the rest, and
+		// id 0, mustn't be marked failed.
+		{"publish fails", &fakePublisher{failId: "blog-service-8"}, []int{7, 8}, []string{"blog-service-7"}, true},
+		{"flush fails", &fakePublisher{flushErr: flushErr}, []int{7, 8, 9}, []string{"blog-service-7", "blog-service-8", "blog-service-9"}, true},
+	}
+	for _, test := range tests {
+		ids, err := publishBatch(ctx, test.publisher, outboxRows(7, 8, 9))
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: err = %v", test.name, err)
+		}
+		if !reflect.DeepEqual(ids, test.wantIds) {
+			t.Errorf("%s: ids = %v, want %v", test.name, ids, test.wantIds)
+		}
+		if !reflect.DeepEqual(test.publisher.published, test.wantPublished) {
+			t.Errorf("%s: published %v, want %v", test.name, test.publisher.published, test.wantPublished)
+		}
+	}
+}
+
+// TestToEventMessageIsStable checks that a row published again after a
+// failed flush goes out as the same event, so the broker and consumers can
+// drop the duplicate.
+func TestToEventMessageIsStable(t *testing.T) {
+	row := model.OutboxMessage{
+		Id:           42,
+		Subject:      events.BlogDeletedSubject,
+		Version:      1,
+		Payload:      []byte(`{"blog_id":1,"author_id":2}`),
+		CreatedAt:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		TraceContext: map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+	}
+
+	first := toEventMessage(&row)
+	row.Attempts, row.LastError = 1, "flush timed out"
+	again := toEventMessage(&row)
+
+	want := &events.Message{
+		Id:           "blog-service-42",
+		Subject:      events.BlogDeletedSubject,
+		Version:      1,
+		OccurredAt:   row.CreatedAt,
+		Data:         row.Payload,
+		TraceContext: row.TraceContext,
+	}
+	if !reflect.DeepEqual(first, want) || !reflect.DeepEqual(again, want) {
+		t.Errorf("toEventMessage = %+v then %+v, want %+v", first, again, want)
+	}
+	if first.Headers()[events.HeaderMsgId] != "blog-service-42" {
+		t.Errorf("Nats-Msg-Id = %q", first.Headers()[events.HeaderMsgId])
+	}
+}
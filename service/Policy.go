@@ -0,0 +1,158 @@
+package service
+
+import (
+	"BlogApplication/auth"
+	"BlogApplication/model"
+	"BlogApplication/repository"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Actions checked by the Policy, as recorded in the audit trail.
+const (
+	ActionEditBlog         = "blog.edit"
+	ActionDeleteBlog       = "blog.delete"
+	ActionBlockBlog        = "blog.block"
+	ActionEditComment      = "comment.edit"
+	ActionDeleteComment    = "comment.delete"
+	ActionReplayDeadLetter = "dead_letter.replay"
+	ActionListDeadLetters  = "dead_letter.list"
+	ActionSetLogLevel      = "log.set_level"
+	ActionListHiddenBlogs  = "blog.list_hidden"
+	ActionViewRevisions    = "blog.view_revisions"
+)
+
+// Policy decides whether the caller in a context may change a resource.
+// Internal services and trusted internal callers may do anything; everyone
+// else is checked against ownership and roles. Denials are written to the
+// audit trail.
+type Policy struct {
+	AuditRepository *repository.AuditRepository
+	Logger          *slog.Logger
+}
+
+// CanEditBlog allows the blog's author and administrators.
+func (policy *Policy) CanEditBlog(ctx context.Context, blog *model.Blog) error {
+	return policy.authorize(ctx, ActionEditBlog, "blog", int64(blog.Id), func(principal *auth.Principal) bool {
+		return principal.UserId == blog.AuthorId || principal.HasRole(auth.RoleAdmin)
+	})
+}
+
+// CanDeleteBlog allows the blog's author and administrators.
+func (policy *Policy) CanDeleteBlog(ctx context.Context, blog *model.Blog) error {
+	return policy.authorize(ctx, ActionDeleteBlog, "blog", int64(blog.Id), func(principal *auth.Principal) bool {
+		return principal.UserId == blog.AuthorId || principal.HasRole(auth.RoleAdmin)
+	})
+}
+
+// CanViewRevisions allows the blog's author and administrators.
+func (policy *Policy) CanViewRevisions(ctx context.Context, blog *model.Blog) error {
+	return policy.authorize(ctx, ActionViewRevisions, "blog", int64(blog.Id), func(principal *auth.Principal) bool {
+		return principal.UserId == blog.AuthorId || principal.HasRole(auth.RoleAdmin)
+	})
+}
+
+// CanBlockBlog allows administrators and moderators.
+func (policy *Policy) CanBlockBlog(ctx context.Context, blog *model.Blog) error {
+	return policy.authorize(ctx, ActionBlockBlog, "blog", int64(blog.Id), func(principal *auth.Principal) bool {
+		return principal.HasRole(auth.RoleAdmin) || principal.HasRole(auth.RoleModerator)
+	})
+}
+
+// CanEditComment allows only the comment's author.
+func (policy *Policy) CanEditComment(ctx context.Context, comment *model.Comment) error {
+	return policy.authorize(ctx, ActionEditComment, "comment", int64(comment.Id), func(principal *auth.Principal) bool {
+		return principal.UserId == comment.AuthorId
+	})
+}
+
+// CanDeleteComment allows the comment's author, the author of the blog it
+// was posted on and moderators.
+func (policy *Policy) CanDeleteComment(ctx context.Context, comment *model.Comment, blog *model.Blog) error {
+	return policy.authorize(ctx, ActionDeleteComment, "comment", int64(comment.Id), func(principal *auth.Principal) bool {
+		return principal.UserId == comment.AuthorId || principal.UserId == blog.AuthorId || principal.HasRole(auth.RoleModerator)
+	})
+}
+
+// CanManageDeadLetters allows only administrators. seq is the dead letter
+// acted on, or zero for the whole queue.
+func (policy *Policy) CanManageDeadLetters(ctx context.Context, action string, seq uint64) error {
+	return policy.authorize(ctx, action, "dead_letter", int64(seq), func(principal *auth.Principal) bool {
+		return principal.HasRole(auth.RoleAdmin)
+	})
+}
+
+// CanSetLogLevel allows only administrators.
+func (policy *Policy) CanSetLogLevel(ctx context.Context) error {
+	return policy.authorize(ctx, ActionSetLogLevel, "logging", 0, func(principal *auth.Principal) bool {
+		return principal.HasRole(auth.RoleAdmin)
+	})
+}
+
+// Viewer describes the caller in ctx as a reader of blogs. Only
+// administrators may include hidden blogs; anonymous callers see listed
+// blogs only.
+func (policy *Policy) Viewer(ctx context.Context, includeHidden bool) (model.BlogViewer, error) {
+	var viewer model.BlogViewer
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		viewer.UserId = principal.UserId
+	}
+	if includeHidden {
+		err := policy.authorize(ctx, ActionListHiddenBlogs, "blog", 0, func(principal *auth.Principal) bool {
+			return principal.HasRole(auth.RoleAdmin)
+		})
+		if err != nil {
+			return model.BlogViewer{}, err
+		}
+		viewer.IncludeHidden = true
+	}
+	return viewer, nil
+}
+
+func (policy *Policy) authorize(ctx context.Context, action string, resource string, resourceId int64, allowed func(principal *auth.Principal) bool) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		if auth.IsTrustedCaller(ctx) {
+			return nil
+		}
+		return model.Unauthenticated("missing caller identity")
+	}
+	if principal.HasRole(auth.RoleInternal) || allowed(principal) {
+		return nil
+	}
+
+	policy.audit(ctx, &model.AuditEntry{
+		ActorId:    principal.UserId,
+		Roles:      principal.Roles,
+		Action:     action,
+		Resource:   resource,
+		ResourceId: resourceId,
+		Reason:     "permission denied",
+		CreatedAt:  time.Now(),
+	})
+	return model.PermissionDenied("user %d isn't allowed to perform %s on %s %d", principal.UserId, action, resource, resourceId)
+}
+
+// audit records a denial. A failure to record it is logged but doesn't change
+// the outcome of the request; a Policy without an AuditRepository records
+// nothing.
+func (policy *Policy) audit(ctx context.Context, entry *model.AuditEntry) {
+	if policy.AuditRepository == nil {
+		return
+	}
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "Audit")
+	defer span.End()
+
+	err := policy.AuditRepository.Create(ctx, entry)
+	if err != nil {
+		policy.Logger.ErrorContext(ctx, "Error recording audit entry", "error", err)
+		span.SetStatus(codes.Error, "Audit failed")
+		return
+	}
+	span.SetStatus(codes.Ok, "Audit successful")
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"BlogApplication/auth"
+	"BlogApplication/model"
+	"context"
+	"testing"
+)
+
+// Callers of the decision matrix: the blog and comment below are written by
+// the author, and the commenter is someone else.
+const (
+	policyAuthor    = 1
+	policyCommenter = 2
+	policyStranger  = 3
+)
+
+var policyCallers = map[string]context.Context{
+	"author":    auth.WithPrincipal(context.Background(), &auth.Principal{UserId: policyAuthor}),
+	"commenter": auth.WithPrincipal(context.Background(), &auth.Principal{UserId: policyCommenter}),
+	"stranger":  auth.WithPrincipal(context.Background(), &auth.Principal{UserId: policyStranger}),
+	"moderator": auth.WithPrincipal(context.Background(), &auth.Principal{UserId: 4, Roles: []string{auth.RoleModerator}}),
+	"admin":     auth.WithPrincipal(context.Background(), &auth.Principal{UserId: 5, Roles: []string{auth.RoleAdmin}}),
+	"internal":  auth.SystemContext(context.Background()),
+	"trusted":   auth.WithTrustedCaller(context.Background()),
+}
+
+func TestPolicyDecisionMatrix(t *testing.T) {
+	policy := &Policy{}
+	blog := &model.Blog{Id: 10, AuthorId: policyAuthor}
+	comment := &model.Comment{Id: 20, BlogId: 10, AuthorId: policyCommenter}
+
+	checks := []struct {
+		name    string
+		check   func(ctx context.Context) error
+		allowed []string
+	}{
+		{"CanEditBlog", func(ctx context.Context) error { return policy.CanEditBlog(ctx, blog) },
+			[]string{"author", "admin"}},
+		{"CanDeleteBlog", func(ctx context.Context) error { return policy.CanDeleteBlog(ctx, blog) },
+			[]string{"author", "admin"}},
+		{"CanViewRevisions", func(ctx context.Context) error { return policy.CanViewRevisions(ctx, blog) },
+			[]string{"author", "admin"}},
+		{"CanBlockBlog", func(ctx context.Context) error { return policy.CanBlockBlog(ctx, blog) },
+			[]string{"moderator", "admin"}},
+		{"CanEditComment", func(ctx context.Context) error { return policy.CanEditComment(ctx, comment) },
+			[]string{"commenter"}},
+		{"CanDeleteComment", func(ctx context.Context) error { return policy.CanDeleteComment(ctx, comment, blog) },
+			[]string{"commenter", "author", "moderator"}},
+		{"CanManageDeadLetters", func(ctx context.Context) error {
+			return policy.CanManageDeadLetters(ctx, ActionReplayDeadLetter, 1)
+		}, []string{"admin"}},
+		{"CanSetLogLevel", func(ctx context.Context) error { return policy.CanSetLogLevel(ctx) },
+			[]string{"admin"}},
+		{"Viewer with hidden blogs", func(ctx context.Context) error {
+			_, err := policy.Viewer(ctx, true)
+			return err
+		}, []string{"admin"}},
+	}
+
+	for _, check := range checks {
+		allowed := map[string]bool{"internal": true, "trusted": true}
+		for _, caller := range check.allowed {
+			allowed[caller] = true
+		}
+		for caller, ctx := range policyCallers {
+			err := check.check(ctx)
+			switch {
+			case allowed[caller] && err != nil:
+				t.Errorf("%s: %s was denied: %v", check.name, caller, err)
+			case !allowed[caller] && model.KindOf(err) != model.KindPermissionDenied:
+				t.Errorf("%s: %s got %v, want permission denied", check.name, caller, err)
+			}
+		}
+
+		if err := check.check(context.Background()); model.KindOf(err) != model.KindUnauthenticated {
+			t.Errorf("%s: anonymous caller got %v, want unauthenticated", check.name, err)
+		}
+	}
+}
+
+func TestPolicyViewer(t *testing.T) {
+	policy := &Policy{}
+
+	viewer, err := policy.Viewer(policyCallers["stranger"], false)
+	if err != nil || viewer != (model.BlogViewer{UserId: policyStranger}) {
+		t.Errorf("Viewer = %+v, %v; want the caller without hidden blogs", viewer, err)
+	}
+
+	// Anonymous readers may list, but only what's listed.
+	viewer, err = policy.Viewer(context.Background(), false)
+	if err != nil || viewer != (model.BlogViewer{}) {
+		t.Errorf("anonymous Viewer = %+v, %v", viewer, err)
+	}
+
+	viewer, err = policy.Viewer(policyCallers["admin"], true)
+	if err != nil || !viewer.IncludeHidden || viewer.UserId != 5 {
+		t.Errorf("admin Viewer = %+v, %v; want hidden blogs included", viewer, err)
+	}
+}
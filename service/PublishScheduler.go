@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PublishScheduler periodically publishes drafts whose scheduled publish time
+// has passed.
+type PublishScheduler struct {
+	BlogService *BlogService
+	Interval    time.Duration
+	Logger      *slog.Logger
+}
+
+// Run blocks, checking for due drafts every Interval until ctx is done.
+func (scheduler *PublishScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			published, err := scheduler.BlogService.PublishDue(ctx, now)
+			if err != nil {
+				scheduler.Logger.ErrorContext(ctx, "Failed to publish scheduled blogs", "error", err)
+			} else if published > 0 {
+				scheduler.Logger.InfoContext(ctx, "Published scheduled blogs", "count", published)
+			}
+		}
+	}
+}
@@ -1,31 +1,39 @@
 package service
 
 import (
+	"BlogApplication/events"
 	"BlogApplication/model"
 	"BlogApplication/repository"
+	"BlogApplication/telemetry"
+	"BlogApplication/useCases"
 	"context"
-	"encoding/json"
+	"log/slog"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
 type ReportService struct {
 	ReportRepository *repository.ReportRepository
+	Outbox           *Outbox
+	Logger           *slog.Logger
 }
 
-func (service *ReportService) FindAllByBlog(ctx context.Context, id int64) ([]model.Report, error) {
+func (service *ReportService) FindAllByBlogPage(ctx context.Context, id int64, page useCases.PageRequest) (*useCases.PagedResult[model.Report], error) {
 	tracer := otel.Tracer("service")
-	ctx, span := tracer.Start(ctx, "FindAllByBlog")
+	ctx, span := tracer.Start(ctx, "FindAllByBlogPage")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.data", "{ \"id\": "+string(id)+" }"))
+	span.SetAttributes(telemetry.BlogId(id))
 
-	reports, _ := service.ReportRepository.FindAllByBlog(ctx, id)
+	result, err := service.ReportRepository.FindAllByBlogPage(ctx, id, page)
+	if err != nil {
+		span.SetStatus(codes.Error, "FindAllByBlogPage failed")
+		return nil, err
+	}
 
-	span.SetStatus(codes.Ok, "FindAllByBlog successful")
-	return reports, nil
+	span.SetStatus(codes.Ok, "FindAllByBlogPage successful")
+	return result, nil
 }
 
 func (service *ReportService) Create(ctx context.Context, report *model.Report) error {
@@ -33,20 +41,26 @@ func (service *ReportService) Create(ctx context.Context, report *model.Report)
 	ctx, span := tracer.Start(ctx, "Create")
 	defer span.End()
 
-	reqData, err := json.Marshal(report)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to marshal request data")
-		return err
-	}
-	span.SetAttributes(attribute.String("request.data", string(reqData)))
+	span.SetAttributes(telemetry.BlogId(int64(report.BlogId)), telemetry.UserId(int64(report.UserId)), telemetry.Text("report.reason", report.Reason))
 
-	err = service.ReportRepository.Create(ctx, report)
+	err := service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		if err := service.ReportRepository.Create(ctx, report); err != nil {
+			return err
+		}
+		return service.Outbox.Enqueue(ctx, events.ReportCreated{
+			ReportId: report.Id,
+			BlogId:   report.BlogId,
+			UserId:   report.UserId,
+			Reason:   report.Reason,
+		})
+	})
 	if err != nil {
 		span.SetStatus(codes.Error, "Create failed")
 		return err
 	}
 
+	telemetry.ReportFiled(ctx)
+	service.Logger.InfoContext(ctx, "Report filed", "report.id", report.Id, "blog.id", report.BlogId)
 	span.SetStatus(codes.Ok, "Create successful")
 	return nil
 }
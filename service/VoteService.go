@@ -1,64 +1,178 @@
 package service
 
 import (
+	"BlogApplication/events"
 	"BlogApplication/model"
 	"BlogApplication/repository"
+	"BlogApplication/telemetry"
+	"context"
 	"fmt"
+	"log/slog"
 
-	"gorm.io/gorm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type VoteService struct {
-	VoteRepo *repository.VoteRepository
+	VoteRepo    *repository.VoteRepository
+	BlogService *BlogService
+	Outbox      *Outbox
+	Logger      *slog.Logger
 }
 
-func (service *VoteService) FindById(id int) (*model.Vote, error) {
-	vote, err := service.VoteRepo.FindById(id)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("vote with id %d not found", id)
-		}
-		return nil, err
-	}
-	return &vote, nil
-}
+// Vote records the user's vote on a blog, replacing any earlier vote of
+// theirs, and updates the blog's counters and status to match.
+func (service *VoteService) Vote(ctx context.Context, blogID int64, userID int64, voteType model.VoteType) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "Vote")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID), telemetry.UserId(userID), telemetry.VoteType(string(voteType)))
 
-func (service *VoteService) Create(vote *model.Vote) error {
+	vote := &model.Vote{UserId: userID, BlogId: blogID, VoteType: voteType}
 	err := vote.Validate()
 	if err != nil {
-		return fmt.Errorf("error validating vote: %w", err)
+		span.SetStatus(codes.Error, "Vote failed")
+		return nil, fmt.Errorf("error validating vote: %w", err)
 	}
-	err = service.VoteRepo.Create(vote)
+	blog, err := service.BlogService.Find(ctx, blogID)
 	if err != nil {
-		return fmt.Errorf("error creating vote: %w", err)
+		span.SetStatus(codes.Error, "Vote failed")
+		return nil, err
 	}
-	return nil
+
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		previous, err := service.VoteRepo.Upsert(ctx, vote)
+		if err != nil {
+			return fmt.Errorf("error saving vote: %w", err)
+		}
+		var previousType *model.VoteType
+		if previous != nil {
+			previousType = &previous.VoteType
+		}
+
+		change := model.TallyChange(previousType, &vote.VoteType)
+		if change.IsZero() {
+			return nil
+		}
+		blog, err = service.BlogService.AdjustVoteCounts(ctx, blogID, change)
+		if err != nil {
+			return err
+		}
+		event := events.VoteCast{BlogId: blogID, UserId: userID, VoteType: string(vote.VoteType)}
+		if previousType != nil {
+			event.PreviousVoteType = string(*previousType)
+		}
+		return service.Outbox.Enqueue(ctx, event)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "Vote failed")
+		return nil, err
+	}
+
+	telemetry.VoteCast(ctx, string(voteType))
+	service.Logger.DebugContext(ctx, "Vote cast", "blog.id", blogID, "vote.type", string(voteType))
+	span.SetStatus(codes.Ok, "Vote successful")
+	return blog, nil
 }
 
-func (service *VoteService) Update(vote *model.Vote) error {
-	err := vote.Validate()
+// RemoveVote retracts the user's vote on a blog, if any, and updates the
+// blog's counters and status to match.
+func (service *VoteService) RemoveVote(ctx context.Context, blogID int64, userID int64) (*model.Blog, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "RemoveVote")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID), telemetry.UserId(userID))
+
+	blog, err := service.BlogService.Find(ctx, blogID)
 	if err != nil {
-		return fmt.Errorf("error validating vote: %w", err)
+		span.SetStatus(codes.Error, "RemoveVote failed")
+		return nil, err
 	}
-	err = service.VoteRepo.Update(vote)
+
+	err = service.Outbox.Atomically(ctx, func(ctx context.Context) error {
+		removed, err := service.VoteRepo.Delete(ctx, blogID, userID)
+		if err != nil {
+			return fmt.Errorf("error removing vote: %w", err)
+		}
+		if removed == nil {
+			return nil
+		}
+		blog, err = service.BlogService.AdjustVoteCounts(ctx, blogID, model.TallyChange(&removed.VoteType, nil))
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error updating vote: %w", err)
+		span.SetStatus(codes.Error, "RemoveVote failed")
+		return nil, err
 	}
-	return nil
+
+	service.Logger.DebugContext(ctx, "Vote removed", "blog.id", blogID)
+	span.SetStatus(codes.Ok, "RemoveVote successful")
+	return blog, nil
 }
 
-func (service *VoteService) Delete(id int) error {
-	err := service.VoteRepo.Delete(id)
+func (service *VoteService) FindAllByBlog(ctx context.Context, blogID int64) ([]model.Vote, error) {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "FindAllByBlog")
+	defer span.End()
+
+	span.SetAttributes(telemetry.BlogId(blogID))
+
+	votes, err := service.VoteRepo.FindAllByBlog(ctx, blogID)
 	if err != nil {
-		return fmt.Errorf("error deleting vote: %w", err)
+		span.SetStatus(codes.Error, "FindAllByBlog failed")
+		return nil, fmt.Errorf("error fetching votes for blog ID %d: %w", blogID, err)
 	}
-	return nil
+
+	span.SetStatus(codes.Ok, "FindAllByBlog successful")
+	return votes, nil
 }
 
-func (service *VoteService) GetAll() ([]model.Vote, error) {
-	votes, err := service.VoteRepo.GetAll()
+// MigrateEmbeddedVotes moves votes stored inline in blog documents into the
+// votes collection and recounts the affected blogs. Blogs are only cleared
+// once all their votes are moved, so an interrupted run can simply be
+// repeated.
+func (service *VoteService) MigrateEmbeddedVotes(ctx context.Context) error {
+	tracer := otel.Tracer("service")
+	ctx, span := tracer.Start(ctx, "MigrateEmbeddedVotes")
+	defer span.End()
+
+	blogs, err := service.BlogService.BlogRepository.FindEmbeddedVotes(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching all votes: %w", err)
+		span.SetStatus(codes.Error, "MigrateEmbeddedVotes failed")
+		return err
 	}
-	return votes, nil
+	for _, embedded := range blogs {
+		blogID := int64(embedded.BlogId)
+		for _, vote := range embedded.Votes {
+			vote.BlogId = blogID
+			if _, err := service.VoteRepo.Insert(ctx, &vote); err != nil {
+				span.SetStatus(codes.Error, "MigrateEmbeddedVotes failed")
+				return err
+			}
+		}
+
+		votes, err := service.VoteRepo.FindAllByBlog(ctx, blogID)
+		if err != nil {
+			span.SetStatus(codes.Error, "MigrateEmbeddedVotes failed")
+			return err
+		}
+		blog, err := service.BlogService.BlogRepository.ReplaceEmbeddedVotes(ctx, blogID, model.TallyOf(votes))
+		if err != nil {
+			span.SetStatus(codes.Error, "MigrateEmbeddedVotes failed")
+			return err
+		}
+		err = service.BlogService.refreshStatus(ctx, &blog)
+		if err != nil {
+			span.SetStatus(codes.Error, "MigrateEmbeddedVotes failed")
+			return err
+		}
+	}
+
+	if len(blogs) > 0 {
+		service.Logger.InfoContext(ctx, "Embedded votes migrated", "blogs", len(blogs))
+	}
+	span.SetStatus(codes.Ok, "MigrateEmbeddedVotes successful")
+	return nil
 }
@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Span attribute keys. Ids are recorded as integers and enums as their
+// string value, so traces can be searched by them.
+const (
+	BlogIdKey        = attribute.Key("blog.id")
+	RevisionIdKey    = attribute.Key("blog.revision.id")
+	TopicKey         = attribute.Key("blog.topic")
+	StatusKey        = attribute.Key("blog.status")
+	UserIdKey        = attribute.Key("user.id")
+	CommentIdKey     = attribute.Key("comment.id")
+	ReportIdKey      = attribute.Key("report.id")
+	VoteTypeKey      = attribute.Key("vote.type")
+	PageSizeKey      = attribute.Key("page.size")
+	DeadLetterSeqKey = attribute.Key("dead_letter.sequence")
+)
+
+// RedactedText replaces free text when the policy keeps none of it.
+const RedactedText = "[redacted]"
+
+func BlogId(id int64) attribute.KeyValue          { return BlogIdKey.Int64(id) }
+func RevisionId(id int64) attribute.KeyValue      { return RevisionIdKey.Int64(id) }
+func Topic(topic string) attribute.KeyValue       { return TopicKey.String(topic) }
+func Status(status string) attribute.KeyValue     { return StatusKey.String(status) }
+func UserId(id int64) attribute.KeyValue          { return UserIdKey.Int64(id) }
+func CommentId(id int64) attribute.KeyValue       { return CommentIdKey.Int64(id) }
+func ReportId(id int64) attribute.KeyValue        { return ReportIdKey.Int64(id) }
+func VoteType(voteType string) attribute.KeyValue { return VoteTypeKey.String(voteType) }
+func PageSize(size int64) attribute.KeyValue      { return PageSizeKey.Int64(size) }
+func DeadLetterSeq(seq uint64) attribute.KeyValue { return DeadLetterSeqKey.Int64(int64(seq)) }
+
+// Text records user-written text, such as a title or a comment, cut to the
+// policy's MaxTextLength characters, or redacted if the policy keeps none.
+func Text(key string, value string) attribute.KeyValue {
+	limit := CurrentPolicy().MaxTextLength
+	if limit <= 0 {
+		return attribute.String(key, RedactedText)
+	}
+	if utf8.RuneCountInString(value) <= limit {
+		return attribute.String(key, value)
+	}
+
+	cut := 0
+	for i := 0; i < limit; i++ {
+		_, size := utf8.DecodeRuneInString(value[cut:])
+		cut += size
+	}
+	return attribute.String(key, value[:cut]+"…")
+}
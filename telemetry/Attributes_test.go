@@ -0,0 +1,30 @@
+package telemetry
+
+import "testing"
+
+// withPolicy sets p for the rest of the test.
+func withPolicy(t *testing.T, p Policy) {
+	previous := CurrentPolicy()
+	SetPolicy(p)
+	t.Cleanup(func() { SetPolicy(previous) })
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		limit int
+		value string
+		want  string
+	}{
+		{5, "short", "short"},
+		{5, "a longer title", "a lon…"},
+		{3, "Ünïcödé", "Ünï…"},
+		{0, "anything", RedactedText},
+	}
+	for _, test := range tests {
+		withPolicy(t, Policy{MaxTextLength: test.limit})
+		got := Text("blog.title", test.value)
+		if got.Key != "blog.title" || got.Value.AsString() != test.want {
+			t.Errorf("Text(%q) with limit %d = %s=%q, want %q", test.value, test.limit, got.Key, got.Value.AsString(), test.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Instruments are created on the global meter, which forwards to whichever
+// provider main installs, so they can be package variables.
+var meter = otel.Meter("BlogApplication")
+
+var (
+	commentsCreated = int64Counter("blog.comments.created", "Comments created.")
+	votesCast       = int64Counter("blog.votes.cast", "Votes cast, by vote type.")
+	reportsFiled    = int64Counter("blog.reports.filed", "Reports filed against blogs.")
+	publishFailures = int64Counter("messaging.publish.failures", "Messages the broker refused, by subject.")
+	mongoDuration   = float64Histogram("db.client.operation.duration", "ms", "Duration of MongoDB commands, by command and outcome.")
+)
+
+func int64Counter(name string, description string) metric.Int64Counter {
+	counter, err := meter.Int64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return counter
+}
+
+func float64Histogram(name string, unit string, description string) metric.Float64Histogram {
+	histogram, err := meter.Float64Histogram(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return histogram
+}
+
+func CommentCreated(ctx context.Context) {
+	commentsCreated.Add(ctx, 1)
+}
+
+func VoteCast(ctx context.Context, voteType string) {
+	votesCast.Add(ctx, 1, metric.WithAttributes(VoteType(voteType)))
+}
+
+func ReportFiled(ctx context.Context) {
+	reportsFiled.Add(ctx, 1)
+}
+
+func PublishFailed(ctx context.Context, subject string) {
+	publishFailures.Add(ctx, 1, metric.WithAttributes(attribute.String("messaging.destination.name", subject)))
+}
+
+// BlogCount is how many blogs have a status and topic.
+type BlogCount struct {
+	Status string
+	Topic  string
+	Count  int64
+}
+
+// ObserveBlogs reports the number of blogs by status and topic, calling
+// count whenever metrics are collected.
+func ObserveBlogs(count func(ctx context.Context) ([]BlogCount, error)) error {
+	gauge, err := meter.Int64ObservableGauge("blog.blogs", metric.WithDescription("Blogs, by status and topic."))
+	if err != nil {
+		return err
+	}
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		counts, err := count(ctx)
+		if err != nil {
+			return err
+		}
+		for _, blogs := range counts {
+			observer.ObserveInt64(gauge, blogs.Count, metric.WithAttributes(Status(blogs.Status), Topic(blogs.Topic)))
+		}
+		return nil
+	}, gauge)
+	return err
+}
+
+// ObserveOutboxBacklog reports the number of unsent outbox messages, calling
+// count whenever metrics are collected.
+func ObserveOutboxBacklog(count func(ctx context.Context) (int64, error)) error {
+	_, err := meter.Int64ObservableGauge("outbox.backlog",
+		metric.WithDescription("Outbox messages not yet sent to the broker."),
+		metric.WithInt64Callback(func(ctx context.Context, observer metric.Int64Observer) error {
+			backlog, err := count(ctx)
+			if err != nil {
+				return err
+			}
+			observer.Observe(backlog)
+			return nil
+		}),
+	)
+	return err
+}
+
+// MongoMonitor times every command the driver sends, then hands the event
+// on to next, which may be nil.
+func MongoMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	if next == nil {
+		next = &event.CommandMonitor{}
+	}
+	return &event.CommandMonitor{
+		Started: next.Started,
+		Succeeded: func(ctx context.Context, succeeded *event.CommandSucceededEvent) {
+			recordMongoCommand(ctx, succeeded.CommandFinishedEvent, "ok")
+			if next.Succeeded != nil {
+				next.Succeeded(ctx, succeeded)
+			}
+		},
+		Failed: func(ctx context.Context, failed *event.CommandFailedEvent) {
+			recordMongoCommand(ctx, failed.CommandFinishedEvent, "error")
+			if next.Failed != nil {
+				next.Failed(ctx, failed)
+			}
+		},
+	}
+}
+
+func recordMongoCommand(ctx context.Context, finished event.CommandFinishedEvent, outcome string) {
+	mongoDuration.Record(ctx, float64(finished.Duration.Microseconds())/1000, metric.WithAttributes(
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.namespace", finished.DatabaseName),
+		attribute.String("db.operation.name", finished.CommandName),
+		attribute.String("db.operation.outcome", outcome),
+	))
+}
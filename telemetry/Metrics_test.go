@@ -0,0 +1,149 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// testReader installs a global meter provider the package's instruments
+// forward to. The global provider can only be set once, so every test
+// shares it and looks at what its own calls added.
+var testReader = sync.OnceValue(func() *sdkmetric.ManualReader {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	return reader
+})
+
+func collect(t *testing.T) map[string]metricdata.Aggregation {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := testReader().Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	metrics := map[string]metricdata.Aggregation{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+// sumOf returns the value of a counter's data point with the given
+// attributes.
+func sumOf(metrics map[string]metricdata.Aggregation, name string, attrs ...attribute.KeyValue) int64 {
+	sum, _ := metrics[name].(metricdata.Sum[int64])
+	want := attribute.NewSet(attrs...)
+	for _, point := range sum.DataPoints {
+		if point.Attributes.Equals(&want) {
+			return point.Value
+		}
+	}
+	return 0
+}
+
+func TestDomainCounters(t *testing.T) {
+	testReader()
+	ctx := context.Background()
+	before := collect(t)
+
+	CommentCreated(ctx)
+	VoteCast(ctx, "UPVOTE")
+	VoteCast(ctx, "UPVOTE")
+	VoteCast(ctx, "DOWNVOTE")
+	ReportFiled(ctx)
+	PublishFailed(ctx, "blog.created")
+
+	after := collect(t)
+	counts := []struct {
+		name  string
+		attrs []attribute.KeyValue
+		want  int64
+	}{
+		{"blog.comments.created", nil, 1},
+		{"blog.votes.cast", []attribute.KeyValue{VoteType("UPVOTE")}, 2},
+		{"blog.votes.cast", []attribute.KeyValue{VoteType("DOWNVOTE")}, 1},
+		{"blog.reports.filed", nil, 1},
+		{"messaging.publish.failures", []attribute.KeyValue{attribute.String("messaging.destination.name", "blog.created")}, 1},
+	}
+	for _, count := range counts {
+		if got := sumOf(after, count.name, count.attrs...) - sumOf(before, count.name, count.attrs...); got != count.want {
+			t.Errorf("%s %v went up by %d, want %d", count.name, count.attrs, got, count.want)
+		}
+	}
+}
+
+func TestObservedGauges(t *testing.T) {
+	testReader()
+	err := ObserveBlogs(func(ctx context.Context) ([]BlogCount, error) {
+		return []BlogCount{{Status: "PUBLISHED", Topic: "FOOD", Count: 3}, {Status: "DRAFT", Topic: "ART", Count: 1}}, nil
+	})
+	if err != nil {
+		t.Fatalf("ObserveBlogs: %v", err)
+	}
+	if err := ObserveOutboxBacklog(func(ctx context.Context) (int64, error) { return 7, nil }); err != nil {
+		t.Fatalf("ObserveOutboxBacklog: %v", err)
+	}
+
+	metrics := collect(t)
+	blogs, _ := metrics["blog.blogs"].(metricdata.Gauge[int64])
+	want := map[attribute.Set]int64{
+		attribute.NewSet(Status("PUBLISHED"), Topic("FOOD")): 3,
+		attribute.NewSet(Status("DRAFT"), Topic("ART")):      1,
+	}
+	if len(blogs.DataPoints) != len(want) {
+		t.Fatalf("blog.blogs has %d points, want %d", len(blogs.DataPoints), len(want))
+	}
+	for _, point := range blogs.DataPoints {
+		if want[point.Attributes] != point.Value {
+			t.Errorf("blog.blogs %v = %d", point.Attributes.ToSlice(), point.Value)
+		}
+	}
+	backlog, _ := metrics["outbox.backlog"].(metricdata.Gauge[int64])
+	if len(backlog.DataPoints) != 1 || backlog.DataPoints[0].Value != 7 {
+		t.Errorf("outbox.backlog = %+v, want 7", backlog.DataPoints)
+	}
+}
+
+func TestMongoMonitorRecordsAndForwards(t *testing.T) {
+	testReader()
+	var forwarded []string
+	monitor := MongoMonitor(&event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) { forwarded = append(forwarded, "succeeded") },
+		Failed:    func(ctx context.Context, e *event.CommandFailedEvent) { forwarded = append(forwarded, "failed") },
+	})
+	finished := event.CommandFinishedEvent{CommandName: "find", DatabaseName: "metrics_test", Duration: 3 * time.Millisecond}
+	ctx := context.Background()
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: "timeout"})
+
+	if len(forwarded) != 2 {
+		t.Errorf("forwarded %v, want both events", forwarded)
+	}
+	histogram, _ := collect(t)["db.client.operation.duration"].(metricdata.Histogram[float64])
+	outcomes := map[string]uint64{}
+	for _, point := range histogram.DataPoints {
+		if namespace, _ := point.Attributes.Value("db.namespace"); namespace.AsString() != "metrics_test" {
+			continue
+		}
+		outcome, _ := point.Attributes.Value("db.operation.outcome")
+		outcomes[outcome.AsString()] += point.Count
+		if point.Sum != 3*float64(point.Count) {
+			t.Errorf("%s durations sum to %vms, want 3ms each", outcome.AsString(), point.Sum)
+		}
+	}
+	if outcomes["ok"] != 1 || outcomes["error"] != 1 {
+		t.Errorf("recorded outcomes %v, want one ok and one error", outcomes)
+	}
+
+	// Without a next monitor nothing is forwarded, and nothing panics.
+	MongoMonitor(nil).Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+}
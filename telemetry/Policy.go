@@ -0,0 +1,115 @@
+package telemetry
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const DefaultMaxTextLength = 32
+
+// Policy decides what request data may leave the service on spans.
+type Policy struct {
+	// MaxTextLength is how many characters of free text Text keeps. Zero
+	// redacts free text altogether.
+	MaxTextLength int
+	// DeniedAttributes are keys dropped from every span and span event
+	// before export, including those set by instrumentation libraries.
+	DeniedAttributes []string
+}
+
+var policy atomic.Pointer[Policy]
+
+// SetPolicy replaces the policy used by Text and FilterExporter.
+func SetPolicy(p Policy) {
+	policy.Store(&p)
+}
+
+// CurrentPolicy returns the policy in force, a DefaultMaxTextLength limit
+// and no denied attributes until SetPolicy is called.
+func CurrentPolicy() Policy {
+	if p := policy.Load(); p != nil {
+		return *p
+	}
+	return Policy{MaxTextLength: DefaultMaxTextLength}
+}
+
+func (p Policy) denied() map[attribute.Key]bool {
+	denied := make(map[attribute.Key]bool, len(p.DeniedAttributes))
+	for _, key := range p.DeniedAttributes {
+		denied[attribute.Key(key)] = true
+	}
+	return denied
+}
+
+// FilterExporter wraps an exporter so that denied attributes never reach it.
+func FilterExporter(next sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &filterExporter{next: next}
+}
+
+type filterExporter struct {
+	next sdktrace.SpanExporter
+}
+
+func (exporter *filterExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	denied := CurrentPolicy().denied()
+	if len(denied) == 0 {
+		return exporter.next.ExportSpans(ctx, spans)
+	}
+
+	filtered := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		filtered[i] = filterSpan(span, denied)
+	}
+	return exporter.next.ExportSpans(ctx, filtered)
+}
+
+func (exporter *filterExporter) Shutdown(ctx context.Context) error {
+	return exporter.next.Shutdown(ctx)
+}
+
+// filterSpan returns span without the denied attributes. Read-only spans
+// can't be changed, so a span with any is copied through a stub.
+func filterSpan(span sdktrace.ReadOnlySpan, denied map[attribute.Key]bool) sdktrace.ReadOnlySpan {
+	if !hasDenied(span.Attributes(), denied) && !eventsHaveDenied(span.Events(), denied) {
+		return span
+	}
+
+	stub := tracetest.SpanStubFromReadOnlySpan(span)
+	stub.Attributes = withoutDenied(stub.Attributes, denied)
+	for i := range stub.Events {
+		stub.Events[i].Attributes = withoutDenied(stub.Events[i].Attributes, denied)
+	}
+	return stub.Snapshot()
+}
+
+func eventsHaveDenied(events []sdktrace.Event, denied map[attribute.Key]bool) bool {
+	for _, event := range events {
+		if hasDenied(event.Attributes, denied) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDenied(attributes []attribute.KeyValue, denied map[attribute.Key]bool) bool {
+	for _, kv := range attributes {
+		if denied[kv.Key] {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutDenied(attributes []attribute.KeyValue, denied map[attribute.Key]bool) []attribute.KeyValue {
+	kept := make([]attribute.KeyValue, 0, len(attributes))
+	for _, kv := range attributes {
+		if !denied[kv.Key] {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFilterExporterDropsDeniedAttributes(t *testing.T) {
+	withPolicy(t, Policy{MaxTextLength: DefaultMaxTextLength, DeniedAttributes: []string{"request.data", "db.statement"}})
+	exported := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(FilterExporter(exported)))
+	defer provider.Shutdown(context.Background())
+	tracer := provider.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "Update")
+	span.SetAttributes(BlogId(1), attribute.String("request.data", `{"title":"secret"}`))
+	span.AddEvent("query", trace.WithAttributes(attribute.String("db.statement", "{}"), attribute.Int("db.rows", 1)))
+	span.End()
+	_, clean := tracer.Start(context.Background(), "Find")
+	clean.SetAttributes(BlogId(2))
+	clean.End()
+
+	spans := exported.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	for _, span := range spans {
+		for _, kv := range span.Attributes {
+			if kv.Key == "request.data" {
+				t.Errorf("%s kept request.data", span.Name)
+			}
+		}
+		for _, event := range span.Events {
+			for _, kv := range event.Attributes {
+				if kv.Key == "db.statement" {
+					t.Errorf("%s event %s kept db.statement", span.Name, event.Name)
+				}
+			}
+		}
+	}
+	if got := spans[0].Attributes; len(got) != 1 || got[0] != BlogId(1) {
+		t.Errorf("Update attributes = %v, want only the blog id", got)
+	}
+	if got := spans[0].Events[0].Attributes; len(got) != 1 || got[0].Key != "db.rows" {
+		t.Errorf("event attributes = %v, want only db.rows", got)
+	}
+	if got := spans[1].Attributes; len(got) != 1 || got[0] != BlogId(2) {
+		t.Errorf("Find attributes = %v", got)
+	}
+}
@@ -0,0 +1,62 @@
+package useCases
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+type PageRequest struct {
+	Size  int64
+	Token string
+}
+
+// Limit returns the requested page size clamped to [1, MaxPageSize], falling
+// back to DefaultPageSize when none was requested.
+func (p PageRequest) Limit() int64 {
+	switch {
+	case p.Size <= 0:
+		return DefaultPageSize
+	case p.Size > MaxPageSize:
+		return MaxPageSize
+	default:
+		return p.Size
+	}
+}
+
+// Cursor identifies the last item of a page by its (date, id) sort key.
+// Paging continues strictly after it, so items inserted in the meantime
+// never shift or repeat the following pages.
+type Cursor struct {
+	Date time.Time `json:"d"`
+	Id   int       `json:"i"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Cursor decodes the page token, returning nil for the first page.
+func (p PageRequest) Cursor() (*Cursor, error) {
+	if p.Token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(p.Token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id <= 0 {
+		return nil, ErrInvalidPageToken
+	}
+	return &cursor, nil
+}
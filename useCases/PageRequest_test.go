@@ -0,0 +1,60 @@
+package useCases
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPageRequestLimit(t *testing.T) {
+	tests := []struct {
+		size int64
+		want int64
+	}{
+		{size: 0, want: DefaultPageSize},
+		{size: -5, want: DefaultPageSize},
+		{size: 1, want: 1},
+		{size: 50, want: 50},
+		{size: MaxPageSize, want: MaxPageSize},
+		{size: MaxPageSize + 1, want: MaxPageSize},
+	}
+	for _, test := range tests {
+		if got := (PageRequest{Size: test.size}).Limit(); got != test.want {
+			t.Errorf("Limit() with size %d = %d, want %d", test.size, got, test.want)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{Date: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC), Id: 42}
+
+	got, err := PageRequest{Token: want.Encode()}.Cursor()
+	if err != nil {
+		t.Fatalf("Cursor: %v", err)
+	}
+	if !got.Date.Equal(want.Date) || got.Id != want.Id {
+		t.Errorf("Cursor() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestCursorOfFirstPage(t *testing.T) {
+	cursor, err := PageRequest{}.Cursor()
+	if err != nil || cursor != nil {
+		t.Errorf("Cursor() without a token = %v, %v; want nil", cursor, err)
+	}
+}
+
+func TestCursorRejectsInvalidTokens(t *testing.T) {
+	tokens := map[string]string{
+		"not base64":    "%%%",
+		"not json":      "bm90IGpzb24",
+		"no id":         Cursor{Date: time.Now()}.Encode(),
+		"padded base64": "e30=",
+		"negative id":   Cursor{Id: -1}.Encode(),
+	}
+	for name, token := range tokens {
+		if _, err := (PageRequest{Token: token}).Cursor(); !errors.Is(err, ErrInvalidPageToken) {
+			t.Errorf("%s: got %v, want ErrInvalidPageToken", name, err)
+		}
+	}
+}
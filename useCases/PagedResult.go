@@ -1,6 +1,7 @@
 package useCases
 
 type PagedResult[T any] struct {
-	Items []T   `json:"items"`
-	Total int64 `json:"total"`
+	Items         []T    `json:"items"`
+	Total         int64  `json:"total"`
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
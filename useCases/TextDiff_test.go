@@ -0,0 +1,99 @@
+package useCases
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []DiffLine
+	}{
+		{
+			name: "identical",
+			old:  "a\nb",
+			new:  "a\nb",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: DiffEqual, Text: "b", OldLine: 2, NewLine: 2},
+			},
+		},
+		{
+			name: "changed line",
+			old:  "a\nb\nc",
+			new:  "a\nx\nc",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: DiffDelete, Text: "b", OldLine: 2},
+				{Op: DiffInsert, Text: "x", NewLine: 2},
+				{Op: DiffEqual, Text: "c", OldLine: 3, NewLine: 3},
+			},
+		},
+		{
+			name: "appended line",
+			old:  "a",
+			new:  "a\nb",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: DiffInsert, Text: "b", NewLine: 2},
+			},
+		},
+		{
+			name: "from empty",
+			old:  "",
+			new:  "a",
+			want: []DiffLine{{Op: DiffInsert, Text: "a", NewLine: 1}},
+		},
+		{
+			name: "to empty",
+			old:  "a",
+			new:  "",
+			want: []DiffLine{{Op: DiffDelete, Text: "a", OldLine: 1}},
+		},
+		{
+			name: "both empty",
+			want: []DiffLine{},
+		},
+		{
+			name: "windows line endings",
+			old:  "a\r\nb",
+			new:  "a\nb",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: DiffEqual, Text: "b", OldLine: 2, NewLine: 2},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DiffLines(test.old, test.new); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DiffLines(%q, %q) = %+v, want %+v", test.old, test.new, got, test.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesRebuildsBothTexts checks that the equal and deleted lines
+// spell out the old text and the equal and inserted lines the new one.
+func TestDiffLinesRebuildsBothTexts(t *testing.T) {
+	old := "one\ntwo\nthree\nfour\nfive"
+	new := "zero\none\nthree\nfour\nfour and a half\nfive"
+
+	var oldLines, newLines []string
+	for _, line := range DiffLines(old, new) {
+		if line.Op != DiffInsert {
+			oldLines = append(oldLines, line.Text)
+		}
+		if line.Op != DiffDelete {
+			newLines = append(newLines, line.Text)
+		}
+	}
+	if !reflect.DeepEqual(oldLines, splitLines(old)) {
+		t.Errorf("old lines = %q", oldLines)
+	}
+	if !reflect.DeepEqual(newLines, splitLines(new)) {
+		t.Errorf("new lines = %q", newLines)
+	}
+}